@@ -0,0 +1,196 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-automata/rivets/events"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AuditStreamName is the JetStream stream holding the Condition audit trail.
+	AuditStreamName = "condition-audit"
+
+	// AuditSubjectPrefix is the subject prefix for audit entries, entries for a
+	// Condition are published on the prefix followed by the Condition ID.
+	AuditSubjectPrefix = "condition.audit"
+
+	AuditEntryVersion int32 = 1
+
+	defaultAuditMaxAge = 30 * 24 * time.Hour
+)
+
+var (
+	ErrAuditRecord  = errors.New("error recording condition audit entry")
+	ErrAuditHistory = errors.New("error reading condition audit history")
+)
+
+// AuditEvent identifies the change recorded by an AuditEntry.
+type AuditEvent string
+
+const (
+	// AuditStateTransition is recorded when the Condition State changes.
+	AuditStateTransition AuditEvent = "stateTransition"
+
+	// AuditStatusChange is recorded when the Condition status is updated without a change in State.
+	AuditStatusChange AuditEvent = "statusChange"
+)
+
+// AuditSubject returns the subject the audit entries for the Condition are published on.
+func AuditSubject(conditionID string) string {
+	return AuditSubjectPrefix + "." + conditionID
+}
+
+// AuditEntry is a single change in the audit trail of a Condition.
+//
+// nolint:govet // fieldalignment struct is easier to read in the current format
+type AuditEntry struct {
+	ConditionID  uuid.UUID       `json:"conditionID"`
+	FacilityCode string          `json:"facilityCode"`
+	Event        AuditEvent      `json:"event"`
+	FromState    State           `json:"fromState,omitempty"`
+	ToState      State           `json:"toState"`
+	Status       json.RawMessage `json:"status,omitempty"`
+	WorkerID     string          `json:"worker,omitempty"`
+	TraceID      string          `json:"traceID,omitempty"`
+	SpanID       string          `json:"spanID,omitempty"`
+	Timestamp    time.Time       `json:"ts"`
+	MsgVersion   int32           `json:"msgVersion"`
+
+	// Sequence is the stream sequence of the entry, set when the entry is read back.
+	Sequence uint64 `json:"-"`
+}
+
+type auditConfig struct {
+	maxAge   time.Duration
+	replicas int
+}
+
+type AuditOption func(c *auditConfig)
+
+// WithAuditMaxAge sets the period audit entries are retained for, the default is 30 days.
+func WithAuditMaxAge(d time.Duration) AuditOption {
+	return func(c *auditConfig) {
+		c.maxAge = d
+	}
+}
+
+// WithAuditReplicas sets the number of replicas for the audit stream.
+func WithAuditReplicas(n int) AuditOption {
+	return func(c *auditConfig) {
+		c.replicas = n
+	}
+}
+
+// AuditLog is the append-only audit trail of the Condition state transitions and status changes.
+type AuditLog struct {
+	js nats.JetStreamContext
+}
+
+// NewAuditLog returns an AuditLog on the audit stream, creating the stream if required.
+func NewAuditLog(njs *events.NatsJetstream, opts ...AuditOption) (*AuditLog, error) {
+	cfg := &auditConfig{maxAge: defaultAuditMaxAge, replicas: 1}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	js := events.AsNatsJetStreamContext(njs)
+
+	_, err := js.StreamInfo(AuditStreamName)
+	switch {
+	case err == nil:
+	case errors.Is(err, nats.ErrStreamNotFound):
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:        AuditStreamName,
+			Description: "Condition state transition and status change audit trail",
+			Subjects:    []string{AuditSubjectPrefix + ".>"},
+			Retention:   nats.LimitsPolicy,
+			MaxAge:      cfg.maxAge,
+			Replicas:    cfg.replicas,
+			Storage:     nats.FileStorage,
+		})
+		if err != nil {
+			return nil, errors.Wrap(events.ErrNatsJetstreamAddStream, err.Error())
+		}
+	default:
+		return nil, err
+	}
+
+	return &AuditLog{js: js}, nil
+}
+
+// Record appends the entry to the audit trail of its Condition, the Timestamp is set if unset.
+func (a *AuditLog) Record(ctx context.Context, entry *AuditEntry) error {
+	if entry.ConditionID == uuid.Nil {
+		return errors.Wrap(ErrAuditRecord, "condition ID not set")
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	entry.MsgVersion = AuditEntryVersion
+
+	byt, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(ErrAuditRecord, err.Error())
+	}
+
+	if _, err := a.js.Publish(AuditSubject(entry.ConditionID.String()), byt, nats.Context(ctx)); err != nil {
+		return errors.Wrap(ErrAuditRecord, err.Error())
+	}
+
+	return nil
+}
+
+// History returns the audit trail of the Condition in the order recorded,
+// an empty history is returned for a Condition with no entries.
+func (a *AuditLog) History(ctx context.Context, conditionID string) ([]*AuditEntry, error) {
+	subject := AuditSubject(conditionID)
+	entries := []*AuditEntry{}
+
+	// the subscription below blocks for entries when there are none
+	if _, err := a.js.GetLastMsg(AuditStreamName, subject, nats.Context(ctx)); err != nil {
+		if errors.Is(err, nats.ErrMsgNotFound) {
+			return entries, nil
+		}
+
+		return nil, errors.Wrap(ErrAuditHistory, err.Error())
+	}
+
+	sub, err := a.js.SubscribeSync(subject, nats.OrderedConsumer(), nats.DeliverAll(), nats.BindStream(AuditStreamName))
+	if err != nil {
+		return nil, errors.Wrap(ErrAuditHistory, err.Error())
+	}
+
+	// nolint:errcheck // nothing useful to do with this error
+	defer sub.Unsubscribe()
+
+	for {
+		msg, err := sub.NextMsgWithContext(ctx)
+		if err != nil {
+			return nil, errors.Wrap(ErrAuditHistory, err.Error())
+		}
+
+		meta, err := msg.Metadata()
+		if err != nil {
+			return nil, errors.Wrap(ErrAuditHistory, err.Error())
+		}
+
+		entry := &AuditEntry{}
+		if err := json.Unmarshal(msg.Data, entry); err != nil {
+			return nil, errors.Wrap(ErrAuditHistory, "entry "+err.Error())
+		}
+
+		entry.Sequence = meta.Sequence.Stream
+		entries = append(entries, entry)
+
+		if meta.NumPending == 0 {
+			return entries, nil
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package condition
+
+//nolint:all // test file
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/metal-automata/rivets/events"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	srv := startJetStreamServer(t)
+	defer shutdownJetStream(t, srv)
+	nc, js := jetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	audit, err := NewAuditLog(evJS)
+	require.NoError(t, err)
+
+	// binds to the existing stream
+	_, err = NewAuditLog(evJS)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	conditionID := uuid.New()
+
+	history, err := audit.History(ctx, conditionID.String())
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	err = audit.Record(ctx, &AuditEntry{ToState: Active})
+	assert.True(t, errors.Is(err, ErrAuditRecord))
+
+	handle, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: string(FirmwareInstall)})
+	require.NoError(t, err)
+
+	worker := NewStatusStore(handle, "flasher-abc", WithAuditLog(audit))
+
+	// every change is recorded, beyond the messages retained in the StatusRecord
+	publish := []struct {
+		state State
+		msg   string
+	}{
+		{Pending, "queued"},
+		{Active, "downloading"},
+		{Active, "installing bmc"},
+		{Active, "installing bios"},
+		{Active, "installing nic"},
+		{Active, "installing drive"},
+		{Active, "powering on"},
+		{Failed, "nic install failed"},
+	}
+
+	for _, p := range publish {
+		sr := NewTaskStatusRecord(p.msg)
+		sv := &StatusValue{State: string(p.state), Status: sr.MustMarshal(), TraceID: "trace-123"}
+		require.NoError(t, worker.Publish("fac13", conditionID.String(), sv))
+	}
+
+	// a rejected transition is not recorded
+	err = worker.Publish("fac13", conditionID.String(), &StatusValue{State: string(Active)})
+	assert.True(t, errors.Is(err, ErrInvalidStatusTransition))
+
+	// entries of other conditions are not included
+	require.NoError(t, worker.Publish("fac13", uuid.NewString(), &StatusValue{State: string(Pending)}))
+
+	history, err = audit.History(ctx, conditionID.String())
+	require.NoError(t, err)
+	require.Len(t, history, len(publish))
+
+	assert.Equal(t, AuditStateTransition, history[0].Event)
+	assert.Equal(t, State(""), history[0].FromState)
+	assert.Equal(t, Pending, history[0].ToState)
+
+	assert.Equal(t, AuditStateTransition, history[1].Event)
+	assert.Equal(t, Pending, history[1].FromState)
+	assert.Equal(t, Active, history[1].ToState)
+
+	assert.Equal(t, AuditStatusChange, history[2].Event)
+
+	last := history[len(history)-1]
+	assert.Equal(t, AuditStateTransition, last.Event)
+	assert.Equal(t, Failed, last.ToState)
+	assert.Equal(t, "flasher-abc", last.WorkerID)
+	assert.Equal(t, "trace-123", last.TraceID)
+	assert.Equal(t, "fac13", last.FacilityCode)
+
+	sr, err := StatusRecordFromMessage(last.Status)
+	require.NoError(t, err)
+	assert.Equal(t, "nic install failed", sr.Last())
+
+	for i := 1; i < len(history); i++ {
+		assert.Greater(t, history[i].Sequence, history[i-1].Sequence)
+		assert.False(t, history[i].Timestamp.Before(history[i-1].Timestamp))
+	}
+}
@@ -27,16 +27,18 @@ type BiosControlTaskParameters struct {
 	// Identifier for the Asset in the Asset store.
 	//
 	// Required: true
-	AssetID uuid.UUID `json:"asset_id"`
+	AssetID uuid.UUID `json:"asset_id" jsonschema:"required"`
 
 	// The bios control action to be performed
 	//
 	// Required: true
-	Action BiosControlAction `json:"action"`
+	Action BiosControlAction `json:"action" jsonschema:"required"`
 
 	// The URL for the bios configuration settings file.
 	// Needed for BiosControlAction.SetConfig
 	//
+	// Files staged in a NATS object store are referred to with a nats-obj://<bucket>/<name> URL.
+	//
 	// Required: false
 	BiosConfigURL *url.URL `json:"bios_config_url,omitempty"`
 }
@@ -71,3 +73,21 @@ func NewBiosControlParametersFromCondition(condition *Condition) (*BiosControlTa
 
 	return b, err
 }
+
+// Validate implements the Parameters interface.
+func (p *BiosControlTaskParameters) Validate() error {
+	v := &fieldValidator{}
+	v.requireID("asset_id", p.AssetID)
+	v.oneOf("action", string(p.Action), string(ResetConfig), string(SetConfig))
+
+	if p.Action == SetConfig {
+		switch {
+		case p.BiosConfigURL == nil:
+			v.add("bios_config_url", nil, "required for action "+string(SetConfig))
+		case !p.BiosConfigURL.IsAbs():
+			v.add("bios_config_url", p.BiosConfigURL.String(), "expected an absolute URL")
+		}
+	}
+
+	return v.err()
+}
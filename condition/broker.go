@@ -35,9 +35,9 @@ const (
 )
 
 type BrokerTaskParameters struct {
-	AssetID       uuid.UUID           `json:"asset_id"`
-	Action        BrokerAction        `json:"action"`
-	Purpose       BrokerActionPurpose `json:"action_purpose"`
+	AssetID       uuid.UUID           `json:"asset_id" jsonschema:"required"`
+	Action        BrokerAction        `json:"action" jsonschema:"required"`
+	Purpose       BrokerActionPurpose `json:"action_purpose" jsonschema:"required"`
 	ServerAcquire *ServerAcquire      `json:"acquire,omitempty"`
 	ServerRelease *ServerRelease      `json:"release,omitempty"`
 }
@@ -104,3 +104,13 @@ func NewBrokerTaskParameters(assetID uuid.UUID, action BrokerAction, purpose Bro
 
 	return nil
 }
+
+// Validate implements the Parameters interface.
+func (p *BrokerTaskParameters) Validate() error {
+	v := &fieldValidator{}
+	v.requireID("asset_id", p.AssetID)
+	v.oneOf("action", string(p.Action), string(AcquireServer), string(ReleaseServer))
+	v.oneOf("action_purpose", string(p.Purpose), string(PurposeFirmwareInstall))
+
+	return v.err()
+}
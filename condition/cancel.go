@@ -0,0 +1,107 @@
+package condition
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-automata/rivets/events/pkg/kv"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CancellationKVBucket is the KV bucket holding Condition cancellation requests.
+	CancellationKVBucket = "condition-cancellations"
+
+	CancellationRequestVersion int32 = 1
+)
+
+var (
+	// ErrConditionCanceled is the cause of a cancellation context being canceled.
+	ErrConditionCanceled = errors.New("condition canceled")
+)
+
+// CancellationKVKey returns the KV key for a Condition cancellation request,
+// this is keyed the same as the Condition StatusValue.
+func CancellationKVKey(facilityCode, conditionID string) string {
+	return StatusValueKVKey(facilityCode, conditionID)
+}
+
+// CancellationRequest is a request to stop work on a Condition.
+//
+// The worker executing the Condition stops and reports the Canceled state through its StatusValue.
+type CancellationRequest struct {
+	ConditionID uuid.UUID `json:"conditionID"`
+	RequestedBy string    `json:"requestedBy,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	RequestedAt time.Time `json:"requestedAt"`
+	MsgVersion  int32     `json:"msgVersion"`
+}
+
+// RequestCancellation records a cancellation request for the Condition in the facility.
+func RequestCancellation(handle nats.KeyValue, facilityCode string, req *CancellationRequest) error {
+	if req.RequestedAt.IsZero() {
+		req.RequestedAt = time.Now()
+	}
+
+	req.MsgVersion = CancellationRequestVersion
+
+	key := CancellationKVKey(facilityCode, req.ConditionID.String())
+	if _, err := kv.NewTyped[CancellationRequest](handle).Put(key, *req); err != nil {
+		return errors.Wrap(err, "recording cancellation request")
+	}
+
+	return nil
+}
+
+// GetCancellation returns the cancellation request for the Condition,
+// nats.ErrKeyNotFound is returned when no cancellation was requested.
+func GetCancellation(handle nats.KeyValue, facilityCode, conditionID string) (*CancellationRequest, error) {
+	entry, err := kv.NewTyped[CancellationRequest](handle).Get(CancellationKVKey(facilityCode, conditionID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry.Value, nil
+}
+
+// CancellationContext returns a context for the worker executing the Condition, which is canceled
+// when a cancellation is requested, including one requested before this call.
+//
+// The context.Cause of a canceled context wraps ErrConditionCanceled when the cancellation
+// was requested. The returned CancelFunc must be called once work on the Condition is done.
+func CancellationContext(ctx context.Context, handle nats.KeyValue, facilityCode,
+	conditionID string) (context.Context, context.CancelFunc, error) {
+	cctx, cancelCause := context.WithCancelCause(ctx)
+	cancel := func() { cancelCause(context.Canceled) }
+
+	key := CancellationKVKey(facilityCode, conditionID)
+
+	updates, err := kv.NewTyped[CancellationRequest](handle).Watch(cctx, []string{key}, kv.WatchIgnoreDeletes())
+	if err != nil {
+		cancel()
+		return nil, nil, errors.Wrap(err, "watching for cancellation request")
+	}
+
+	go func() {
+		for update := range updates {
+			if update.Op != kv.OpPut {
+				continue
+			}
+
+			cause := fmt.Errorf("%w: condition=%s", ErrConditionCanceled, conditionID)
+			if update.Err == nil {
+				cause = fmt.Errorf("%w: condition=%s requestedBy=%s reason=%s",
+					ErrConditionCanceled, conditionID, update.Value.RequestedBy, update.Value.Reason)
+			}
+
+			cancelCause(cause)
+
+			return
+		}
+	}()
+
+	return cctx, cancel, nil
+}
@@ -0,0 +1,88 @@
+package condition
+
+//nolint:all // test file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanceledTransitions(t *testing.T) {
+	assert.True(t, Pending.TransitionValid(Canceled))
+	assert.True(t, Active.TransitionValid(Canceled))
+	assert.False(t, Succeeded.TransitionValid(Canceled))
+	assert.False(t, Canceled.TransitionValid(Active))
+	assert.True(t, StateIsComplete(Canceled))
+	assert.True(t, StateIsValid(Canceled))
+}
+
+func TestCancellationContext(t *testing.T) {
+	srv := startJetStreamServer(t)
+	defer shutdownJetStream(t, srv)
+	_, js := jetStreamContext(t, srv)
+
+	handle, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: CancellationKVBucket})
+	require.NoError(t, err)
+
+	facilityCode := "fac13"
+	conditionID := uuid.New()
+	otherID := uuid.New()
+
+	_, err = GetCancellation(handle, facilityCode, conditionID.String())
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+
+	ctx, cancel, err := CancellationContext(context.Background(), handle, facilityCode, conditionID.String())
+	require.NoError(t, err)
+	defer cancel()
+
+	// a cancellation for another condition is ignored
+	require.NoError(t, RequestCancellation(handle, facilityCode, &CancellationRequest{ConditionID: otherID}))
+
+	select {
+	case <-ctx.Done():
+		t.Fatal("unexpected cancellation")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	req := &CancellationRequest{ConditionID: conditionID, RequestedBy: "operator", Reason: "maintenance"}
+	require.NoError(t, RequestCancellation(handle, facilityCode, req))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for cancellation")
+	}
+
+	require.ErrorIs(t, context.Cause(ctx), ErrConditionCanceled)
+	assert.Contains(t, context.Cause(ctx).Error(), "maintenance")
+
+	got, err := GetCancellation(handle, facilityCode, conditionID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "operator", got.RequestedBy)
+	assert.False(t, got.RequestedAt.IsZero())
+
+	// a cancellation requested before the worker starts cancels immediately
+	ctx2, cancel2, err := CancellationContext(context.Background(), handle, facilityCode, conditionID.String())
+	require.NoError(t, err)
+	defer cancel2()
+
+	select {
+	case <-ctx2.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for cancellation")
+	}
+	require.ErrorIs(t, context.Cause(ctx2), ErrConditionCanceled)
+
+	// canceling the returned context is not a condition cancellation
+	ctx3, cancel3, err := CancellationContext(context.Background(), handle, facilityCode, uuid.New().String())
+	require.NoError(t, err)
+	cancel3()
+	<-ctx3.Done()
+	require.NotErrorIs(t, context.Cause(ctx3), ErrConditionCanceled)
+}
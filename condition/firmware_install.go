@@ -2,6 +2,7 @@ package condition
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/google/uuid"
 )
@@ -16,7 +17,7 @@ const (
 // nolint:govet // fieldalignment struct is easier to read in the current format
 type FirmwareInstallTaskParameters struct {
 	// Inventory identifier for the asset to install firmware on.
-	AssetID uuid.UUID `json:"asset_id"`
+	AssetID uuid.UUID `json:"asset_id" jsonschema:"required"`
 
 	// Reset device BMC before firmware install
 	ResetBMCBeforeInstall bool `json:"reset_bmc_before_install,omitempty"`
@@ -79,3 +80,24 @@ type Firmware struct {
 	InstallInband bool     `yaml:"install_inband" json:"install_inband"`
 	Oem           bool     `yaml:"oem" json:"oem"`
 }
+
+// Validate implements the Parameters interface.
+func (p *FirmwareInstallTaskParameters) Validate() error {
+	v := &fieldValidator{}
+	v.requireID("asset_id", p.AssetID)
+
+	if p.FirmwareSetID == uuid.Nil && len(p.Firmwares) == 0 {
+		v.add("firmware_set_id", nil, "required when no firmwares are listed")
+	}
+
+	for idx := range p.Firmwares {
+		fw := &p.Firmwares[idx]
+		field := fmt.Sprintf("firmwares.%d.", idx)
+
+		v.requireString(field+"URL", fw.URL)
+		v.requireString(field+"version", fw.Version)
+		v.requireString(field+"component", fw.Component)
+	}
+
+	return v.err()
+}
@@ -28,10 +28,10 @@ type InventoryTaskParameters struct {
 	CollectFirwmareStatus bool `json:"collect_firmware_status"`
 
 	// Method defaults to Outofband
-	Method InventoryMethod `json:"inventory_method"`
+	Method InventoryMethod `json:"inventory_method" jsonschema:"required"`
 
 	// Asset identifier.
-	AssetID uuid.UUID `json:"asset_id"`
+	AssetID uuid.UUID `json:"asset_id" jsonschema:"required"`
 }
 
 func NewInventoryTaskParameters(assetID uuid.UUID, method InventoryMethod, collectFirmwareStatus, collectBiosCfg bool) *InventoryTaskParameters {
@@ -77,3 +77,12 @@ func (p *InventoryTaskParameters) Unmarshal(r json.RawMessage) error {
 func (p *InventoryTaskParameters) Marshal() (json.RawMessage, error) {
 	return json.Marshal(p)
 }
+
+// Validate implements the Parameters interface.
+func (p *InventoryTaskParameters) Validate() error {
+	v := &fieldValidator{}
+	v.requireID("asset_id", p.AssetID)
+	v.oneOf("inventory_method", string(p.Method), string(InbandInventory), string(OutofbandInventory))
+
+	return v.err()
+}
@@ -0,0 +1,134 @@
+package condition
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrUnknownKind      = errors.New("unknown condition kind")
+	ErrKindRegistered   = errors.New("condition kind already registered")
+	ErrParametersDecode = errors.New("error decoding condition parameters")
+	ErrParametersType   = errors.New("condition parameters type mismatch")
+
+	kindsMu sync.RWMutex
+	kinds   = map[Kind]func() Parameters{}
+)
+
+func init() {
+	builtin := map[Kind]func() Parameters{
+		BiosControl:           func() Parameters { return &BiosControlTaskParameters{} },
+		Broker:                func() Parameters { return &BrokerTaskParameters{} },
+		BrokerAcquireServer:   func() Parameters { return &BrokerTaskParameters{} },
+		BrokerReleaseServer:   func() Parameters { return &BrokerTaskParameters{} },
+		FirmwareInstall:       func() Parameters { return &FirmwareInstallTaskParameters{} },
+		FirmwareInstallInband: func() Parameters { return &FirmwareInstallTaskParameters{} },
+		Inventory:             func() Parameters { return &InventoryTaskParameters{} },
+		ServerControl:         func() Parameters { return &ServerControlTaskParameters{} },
+		VirtualMediaMount:     func() Parameters { return &VirtualMediaTaskParameters{} },
+	}
+
+	for kind, newParams := range builtin {
+		MustRegisterKind(kind, newParams)
+	}
+}
+
+// RegisterKind registers the Parameters type for a Condition Kind,
+// newParams returns a pointer to a new zero value of the Parameters type.
+//
+// Controllers implementing Condition kinds not defined in this package register them
+// to have their Parameters decoded by DecodeParameters and ParametersAs.
+func RegisterKind(kind Kind, newParams func() Parameters) error {
+	kindsMu.Lock()
+	defer kindsMu.Unlock()
+
+	if _, exists := kinds[kind]; exists {
+		return errors.Wrap(ErrKindRegistered, string(kind))
+	}
+
+	kinds[kind] = newParams
+
+	return nil
+}
+
+// MustRegisterKind registers the Parameters type for a Condition Kind or panics.
+func MustRegisterKind(kind Kind, newParams func() Parameters) {
+	if err := RegisterKind(kind, newParams); err != nil {
+		panic(err)
+	}
+}
+
+// RegisteredKinds returns the registered Condition kinds in sorted order.
+func RegisteredKinds() []Kind {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+
+	registered := make([]Kind, 0, len(kinds))
+	for k := range kinds {
+		registered = append(registered, k)
+	}
+
+	sort.Slice(registered, func(i, j int) bool { return registered[i] < registered[j] })
+
+	return registered
+}
+
+// NewParameters returns a new zero value of the Parameters type registered for the Condition Kind.
+func NewParameters(kind Kind) (Parameters, error) {
+	kindsMu.RLock()
+	newParams, exists := kinds[kind]
+	kindsMu.RUnlock()
+
+	if !exists {
+		return nil, errors.Wrap(ErrUnknownKind, string(kind))
+	}
+
+	return newParams(), nil
+}
+
+// DecodeParameters returns the validated Condition Parameters, decoded into the type registered for its Kind.
+func DecodeParameters(cond *Condition) (Parameters, error) {
+	params, err := NewParameters(cond.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cond.Parameters) > 0 {
+		if err := json.Unmarshal(cond.Parameters, params); err != nil {
+			return nil, errors.Wrap(ErrParametersDecode, fmt.Sprintf("kind=%s: %s", cond.Kind, err.Error()))
+		}
+	}
+
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// ParametersAs returns the validated Condition Parameters as the type P,
+// which is expected to be the pointer type registered for the Condition Kind.
+//
+//	params, err := condition.ParametersAs[*condition.InventoryTaskParameters](cond)
+func ParametersAs[P Parameters](cond *Condition) (P, error) {
+	var zero P
+
+	params, err := DecodeParameters(cond)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := params.(P)
+	if !ok {
+		return zero, errors.Wrap(
+			ErrParametersType,
+			fmt.Sprintf("kind=%s registered type=%T requested type=%T", cond.Kind, params, zero),
+		)
+	}
+
+	return typed, nil
+}
@@ -0,0 +1,77 @@
+package condition
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKindParameters struct {
+	Name string `json:"name"`
+}
+
+func (p *testKindParameters) Validate() error {
+	v := &fieldValidator{}
+	v.requireString("name", p.Name)
+
+	return v.err()
+}
+
+func TestKindRegistry(t *testing.T) {
+	assetID := uuid.New()
+
+	cond := &Condition{
+		Kind:       Inventory,
+		Parameters: MustDefaultInventoryJSON(assetID),
+	}
+
+	params, err := DecodeParameters(cond)
+	require.NoError(t, err)
+	assert.Equal(t, NewInventoryTaskParameters(assetID, OutofbandInventory, true, true), params)
+
+	inventory, err := ParametersAs[*InventoryTaskParameters](cond)
+	require.NoError(t, err)
+	assert.Equal(t, assetID, inventory.AssetID)
+
+	_, err = ParametersAs[*FirmwareInstallTaskParameters](cond)
+	assert.True(t, errors.Is(err, ErrParametersType))
+
+	// invalid parameters
+	cond.Parameters = json.RawMessage(`{"asset_id": "` + assetID.String() + `"}`)
+	_, err = DecodeParameters(cond)
+	assert.True(t, errors.Is(err, ErrInvalidParameters))
+
+	cond.Parameters = json.RawMessage(`{"asset_id": 1}`)
+	_, err = DecodeParameters(cond)
+	assert.True(t, errors.Is(err, ErrParametersDecode))
+
+	// unknown kinds
+	_, err = DecodeParameters(&Condition{Kind: "reticulateSplines"})
+	assert.True(t, errors.Is(err, ErrUnknownKind))
+
+	// third party kinds
+	splinesKind := Kind("reticulateSplines." + uuid.NewString())
+	require.NoError(t, RegisterKind(splinesKind, func() Parameters { return &testKindParameters{} }))
+	assert.Contains(t, RegisteredKinds(), splinesKind)
+
+	err = RegisterKind(splinesKind, func() Parameters { return &testKindParameters{} })
+	assert.True(t, errors.Is(err, ErrKindRegistered))
+
+	splines, err := ParametersAs[*testKindParameters](&Condition{
+		Kind:       splinesKind,
+		Parameters: json.RawMessage(`{"name": "bezier"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "bezier", splines.Name)
+
+	// every builtin kind is registered
+	for _, kind := range []Kind{BiosControl, Broker, BrokerAcquireServer, BrokerReleaseServer, FirmwareInstall,
+		FirmwareInstallInband, Inventory, ServerControl, VirtualMediaMount} {
+		_, err := NewParameters(kind)
+		assert.NoError(t, err, kind)
+	}
+}
@@ -0,0 +1,299 @@
+package condition
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrMigrationRegistered = errors.New("payload migration already registered")
+	ErrMigration           = errors.New("error migrating payload")
+
+	conditionMigrations = &migrator{
+		payload:      "Condition",
+		versionField: "version",
+		current:      ConditionStructVersion,
+		// Conditions published before the version field was set are of the 1.0 layout.
+		unversioned: "1.0",
+		steps:       map[string]migrationStep{},
+	}
+
+	taskMigrations = &migrator{
+		payload:      "Task",
+		versionField: "task_version",
+		current:      TaskVersion1,
+		unversioned:  TaskVersion1,
+		steps:        map[string]migrationStep{},
+	}
+
+	statusValueMigrations = &migrator{
+		payload:      "StatusValue",
+		versionField: "msgVersion",
+		numeric:      true,
+		current:      strconv.Itoa(int(StatusValueVersion)),
+		unversioned:  strconv.Itoa(int(StatusValueVersion)),
+		steps:        map[string]migrationStep{},
+	}
+)
+
+func init() {
+	// The 1.1 Condition added the optional failOnCheckpointError and fault fields,
+	// a 1.0 Condition decodes as is.
+	if err := RegisterConditionMigration("1.0", "1.1", func(map[string]json.RawMessage) error { return nil }); err != nil {
+		panic(err)
+	}
+}
+
+// ErrUnsupportedVersion is returned when a payload version cannot be migrated to the current version.
+type ErrUnsupportedVersion struct {
+	// Payload is the kind of payload, one of Condition, Task or StatusValue.
+	Payload string
+	// Version is the payload version.
+	Version string
+	// Current is the version this package decodes into.
+	Current string
+	// Newer is set when the payload version is newer than the current version.
+	Newer bool
+}
+
+func (e *ErrUnsupportedVersion) Error() string {
+	if e.Newer {
+		return fmt.Sprintf("%s version %s is newer than the supported version %s", e.Payload, e.Version, e.Current)
+	}
+
+	return fmt.Sprintf("%s version %s has no migration to the supported version %s", e.Payload, e.Version, e.Current)
+}
+
+// Migration upgrades a JSON payload in place from one version to the next,
+// the payload version field is set by the caller once the migration returns.
+type Migration func(doc map[string]json.RawMessage) error
+
+type migrationStep struct {
+	to string
+	fn Migration
+}
+
+// migrator holds the migrations for a payload type, keyed by the version migrated from.
+type migrator struct {
+	mu           sync.RWMutex
+	payload      string
+	versionField string
+	numeric      bool
+	current      string
+	unversioned  string
+	steps        map[string]migrationStep
+}
+
+func (m *migrator) register(from, to string, fn Migration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.steps[from]; exists {
+		return errors.Wrap(ErrMigrationRegistered, fmt.Sprintf("%s from version %s", m.payload, from))
+	}
+
+	m.steps[from] = migrationStep{to: to, fn: fn}
+
+	return nil
+}
+
+// migrate returns the payload upgraded to the current version.
+func (m *migrator) migrate(b []byte) ([]byte, error) {
+	doc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, errors.Wrap(ErrMigration, fmt.Sprintf("%s: %s", m.payload, err.Error()))
+	}
+
+	version, err := m.version(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == m.current {
+		return b, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for seen := map[string]bool{}; version != m.current; {
+		step, exists := m.steps[version]
+		if !exists || seen[version] {
+			return nil, &ErrUnsupportedVersion{
+				Payload: m.payload,
+				Version: version,
+				Current: m.current,
+				Newer:   compareVersions(version, m.current) > 0,
+			}
+		}
+
+		seen[version] = true
+
+		if err := step.fn(doc); err != nil {
+			return nil, errors.Wrap(ErrMigration, fmt.Sprintf("%s from version %s: %s", m.payload, version, err.Error()))
+		}
+
+		version = step.to
+	}
+
+	if err := m.setVersion(doc, version); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(doc)
+}
+
+func (m *migrator) version(doc map[string]json.RawMessage) (string, error) {
+	raw, exists := doc[m.versionField]
+	if !exists || string(raw) == "null" {
+		return m.unversioned, nil
+	}
+
+	if m.numeric {
+		var n int64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return "", errors.Wrap(ErrMigration, fmt.Sprintf("%s %s field: %s", m.payload, m.versionField, err.Error()))
+		}
+
+		// the zero value is omitted by payloads written before versioning
+		if n == 0 {
+			return m.unversioned, nil
+		}
+
+		return strconv.FormatInt(n, 10), nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", errors.Wrap(ErrMigration, fmt.Sprintf("%s %s field: %s", m.payload, m.versionField, err.Error()))
+	}
+
+	if s == "" {
+		return m.unversioned, nil
+	}
+
+	return s, nil
+}
+
+func (m *migrator) setVersion(doc map[string]json.RawMessage, version string) error {
+	var v any = version
+	if m.numeric {
+		n, err := strconv.ParseInt(version, 10, 32)
+		if err != nil {
+			return errors.Wrap(ErrMigration, fmt.Sprintf("%s version %s: %s", m.payload, version, err.Error()))
+		}
+
+		v = n
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(ErrMigration, err.Error())
+	}
+
+	doc[m.versionField] = raw
+
+	return nil
+}
+
+// compareVersions compares dotted numeric versions, versions that do not parse compare as equal.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+
+		if i < len(as) {
+			n, err := strconv.Atoi(as[i])
+			if err != nil {
+				return 0
+			}
+
+			x = n
+		}
+
+		if i < len(bs) {
+			n, err := strconv.Atoi(bs[i])
+			if err != nil {
+				return 0
+			}
+
+			y = n
+		}
+
+		switch {
+		case x > y:
+			return 1
+		case x < y:
+			return -1
+		}
+	}
+
+	return 0
+}
+
+// RegisterConditionMigration registers the Migration upgrading a Condition from one version to the next.
+func RegisterConditionMigration(from, to string, fn Migration) error {
+	return conditionMigrations.register(from, to, fn)
+}
+
+// RegisterTaskMigration registers the Migration upgrading a Task from one version to the next.
+func RegisterTaskMigration(from, to string, fn Migration) error {
+	return taskMigrations.register(from, to, fn)
+}
+
+// RegisterStatusValueMigration registers the Migration upgrading a StatusValue from one version to the next.
+func RegisterStatusValueMigration(from, to int32, fn Migration) error {
+	return statusValueMigrations.register(strconv.Itoa(int(from)), strconv.Itoa(int(to)), fn)
+}
+
+// DecodeCondition decodes a Condition payload, upgrading it from older versions to the current version.
+//
+// An *ErrUnsupportedVersion is returned for versions that cannot be upgraded, including newer versions.
+func DecodeCondition(b []byte) (*Condition, error) {
+	migrated, err := conditionMigrations.migrate(b)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Condition{}
+	if err := json.Unmarshal(migrated, c); err != nil {
+		return nil, errors.Wrap(ErrMigration, "Condition: "+err.Error())
+	}
+
+	return c, nil
+}
+
+// DecodeTask decodes a Task payload, upgrading it from older versions to the current version.
+//
+// An *ErrUnsupportedVersion is returned for versions that cannot be upgraded, including newer versions.
+func DecodeTask[P, D any](b []byte) (*Task[P, D], error) {
+	migrated, err := taskMigrations.migrate(b)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Task[P, D]{}
+	if err := json.Unmarshal(migrated, t); err != nil {
+		return nil, errors.Wrap(errInvalidTaskJSON, err.Error())
+	}
+
+	return t, nil
+}
+
+// DecodeStatusValue decodes a StatusValue payload, upgrading it from older versions to the current version.
+//
+// An *ErrUnsupportedVersion is returned for versions that cannot be upgraded, including newer versions.
+func DecodeStatusValue(b []byte) (*StatusValue, error) {
+	migrated, err := statusValueMigrations.migrate(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalStatusValue(migrated)
+}
@@ -0,0 +1,207 @@
+package condition
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtures of the past payload versions as published on the wire
+const (
+	conditionV10Fixture = `{
+		"version": "1.0",
+		"client": "fleet-ops",
+		"traceID": "abc",
+		"spanID": "def",
+		"id": "8f0e9e2f-3d6a-4b8b-a0c2-0c1cc4b3c1d1",
+		"target": "ed483ef9-098e-4892-bfcf-1696c44fd7a9",
+		"kind": "inventory",
+		"parameters": {"asset_id": "ed483ef9-098e-4892-bfcf-1696c44fd7a9", "inventory_method": "outofband"},
+		"state": "pending",
+		"createdAt": "2024-01-02T03:04:05Z"
+	}`
+
+	conditionV11Fixture = `{
+		"version": "1.1",
+		"client": "fleet-ops",
+		"traceID": "abc",
+		"spanID": "def",
+		"id": "8f0e9e2f-3d6a-4b8b-a0c2-0c1cc4b3c1d1",
+		"target": "ed483ef9-098e-4892-bfcf-1696c44fd7a9",
+		"kind": "inventory",
+		"parameters": {"asset_id": "ed483ef9-098e-4892-bfcf-1696c44fd7a9", "inventory_method": "outofband"},
+		"state": "pending",
+		"failOnCheckpointError": true,
+		"fault": {"panic": false, "failAt": "init"},
+		"createdAt": "2024-01-02T03:04:05Z"
+	}`
+
+	taskV10Fixture = `{
+		"task_version": "1.0",
+		"id": "8f0e9e2f-3d6a-4b8b-a0c2-0c1cc4b3c1d1",
+		"kind": "inventory",
+		"state": "active",
+		"status": {"records": [{"ts": "2024-01-02T03:04:05Z", "msg": "collecting inventory"}]},
+		"parameters": {"asset_id": "ed483ef9-098e-4892-bfcf-1696c44fd7a9", "inventory_method": "outofband"},
+		"facility_code": "fac13",
+		"worker_id": "alloy-abc",
+		"traceID": "abc",
+		"spanID": "def"
+	}`
+
+	statusValueV1Fixture = `{
+		"created": "2024-01-02T03:04:05Z",
+		"updated": "2024-01-02T03:05:05Z",
+		"worker": "alloy-abc",
+		"target": "ed483ef9-098e-4892-bfcf-1696c44fd7a9",
+		"state": "active",
+		"status": {"msg": "collecting inventory"},
+		"msgVersion": 1
+	}`
+
+	statusValueUnversionedFixture = `{
+		"created": "2024-01-02T03:04:05Z",
+		"updated": "2024-01-02T03:05:05Z",
+		"worker": "alloy-abc",
+		"target": "ed483ef9-098e-4892-bfcf-1696c44fd7a9",
+		"state": "active",
+		"status": {"msg": "collecting inventory"}
+	}`
+)
+
+func TestDecodeCondition(t *testing.T) {
+	for name, fixture := range map[string]string{
+		"1.0":         conditionV10Fixture,
+		"1.1":         conditionV11Fixture,
+		"unversioned": `{"id": "8f0e9e2f-3d6a-4b8b-a0c2-0c1cc4b3c1d1", "kind": "inventory", "state": "pending"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := DecodeCondition([]byte(fixture))
+			require.NoError(t, err)
+
+			assert.Equal(t, ConditionStructVersion, c.Version)
+			assert.Equal(t, uuid.MustParse("8f0e9e2f-3d6a-4b8b-a0c2-0c1cc4b3c1d1"), c.ID)
+			assert.Equal(t, Inventory, c.Kind)
+			assert.Equal(t, Pending, c.State)
+
+			// round trip
+			again, err := DecodeCondition(c.MustBytes())
+			require.NoError(t, err)
+
+			if c.Parameters != nil {
+				// raw parameters are compacted when encoded
+				assert.JSONEq(t, string(c.Parameters), string(again.Parameters))
+				again.Parameters = c.Parameters
+			}
+			assert.Equal(t, c, again)
+		})
+	}
+
+	c, err := DecodeCondition([]byte(conditionV11Fixture))
+	require.NoError(t, err)
+	assert.True(t, c.FailOnCheckpointError)
+	assert.Equal(t, "init", c.Fault.FailAt)
+
+	params, err := ParametersAs[*InventoryTaskParameters](c)
+	require.NoError(t, err)
+	assert.Equal(t, OutofbandInventory, params.Method)
+}
+
+func TestDecodeUnsupportedVersions(t *testing.T) {
+	_, err := DecodeCondition([]byte(`{"version": "2.0", "kind": "inventory"}`))
+	var errVersion *ErrUnsupportedVersion
+	require.True(t, errors.As(err, &errVersion))
+	assert.Equal(t, "Condition", errVersion.Payload)
+	assert.Equal(t, "2.0", errVersion.Version)
+	assert.True(t, errVersion.Newer)
+
+	_, err = DecodeCondition([]byte(`{"version": "0.9"}`))
+	require.True(t, errors.As(err, &errVersion))
+	assert.False(t, errVersion.Newer)
+
+	_, err = DecodeTask[any, any]([]byte(`{"task_version": "1.1"}`))
+	require.True(t, errors.As(err, &errVersion))
+	assert.Equal(t, "Task", errVersion.Payload)
+	assert.True(t, errVersion.Newer)
+
+	_, err = DecodeStatusValue([]byte(`{"msgVersion": 2}`))
+	require.True(t, errors.As(err, &errVersion))
+	assert.Equal(t, "StatusValue", errVersion.Payload)
+	assert.True(t, errVersion.Newer)
+
+	_, err = DecodeCondition([]byte(`{"version": 1.1}`))
+	assert.True(t, errors.Is(err, ErrMigration))
+}
+
+func TestDecodeTask(t *testing.T) {
+	task, err := DecodeTask[*InventoryTaskParameters, json.RawMessage]([]byte(taskV10Fixture))
+	require.NoError(t, err)
+
+	assert.Equal(t, TaskVersion1, task.StructVersion)
+	assert.Equal(t, Active, task.State)
+	assert.Equal(t, OutofbandInventory, task.Parameters.Method)
+	assert.Equal(t, "collecting inventory", task.Status.Last())
+
+	b, err := task.Marshal()
+	require.NoError(t, err)
+
+	again, err := DecodeTask[*InventoryTaskParameters, json.RawMessage](b)
+	require.NoError(t, err)
+	assert.Equal(t, task.Parameters, again.Parameters)
+	assert.Equal(t, task.Status, again.Status)
+	assert.Equal(t, task.WorkerID, again.WorkerID)
+}
+
+func TestDecodeStatusValue(t *testing.T) {
+	for name, fixture := range map[string]string{
+		"1":           statusValueV1Fixture,
+		"unversioned": statusValueUnversionedFixture,
+	} {
+		t.Run(name, func(t *testing.T) {
+			sv, err := DecodeStatusValue([]byte(fixture))
+			require.NoError(t, err)
+
+			assert.Equal(t, "alloy-abc", sv.WorkerID)
+			assert.Equal(t, string(Active), sv.State)
+
+			again, err := DecodeStatusValue(sv.MustBytes())
+			require.NoError(t, err)
+			assert.Equal(t, StatusValueVersion, again.MsgVersion)
+			assert.Equal(t, sv.WorkerID, again.WorkerID)
+			assert.JSONEq(t, string(sv.Status), string(again.Status))
+		})
+	}
+}
+
+func TestMigrationChain(t *testing.T) {
+	m := &migrator{
+		payload:      "Test",
+		versionField: "v",
+		current:      "3",
+		unversioned:  "1",
+		steps:        map[string]migrationStep{},
+	}
+
+	require.NoError(t, m.register("1", "2", func(doc map[string]json.RawMessage) error {
+		doc["name"] = doc["title"]
+		delete(doc, "title")
+		return nil
+	}))
+	require.NoError(t, m.register("2", "3", func(doc map[string]json.RawMessage) error {
+		doc["labels"] = json.RawMessage(`[]`)
+		return nil
+	}))
+	assert.True(t, errors.Is(m.register("2", "3", nil), ErrMigrationRegistered))
+
+	b, err := m.migrate([]byte(`{"title": "r6515"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"v": "3", "name": "r6515", "labels": []}`, string(b))
+
+	b, err = m.migrate([]byte(`{"v": "2", "name": "r6515"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"v": "3", "name": "r6515", "labels": []}`, string(b))
+}
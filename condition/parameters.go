@@ -0,0 +1,106 @@
+package condition
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// ErrInvalidParameters is wrapped by the errors returned from Parameters.Validate.
+	ErrInvalidParameters = errors.New("invalid condition parameters")
+
+	// Parameters types implemented for each of the Condition kinds.
+	_ Parameters = (*BiosControlTaskParameters)(nil)
+	_ Parameters = (*ServerControlTaskParameters)(nil)
+	_ Parameters = (*FirmwareInstallTaskParameters)(nil)
+	_ Parameters = (*InventoryTaskParameters)(nil)
+	_ Parameters = (*VirtualMediaTaskParameters)(nil)
+	_ Parameters = (*BrokerTaskParameters)(nil)
+)
+
+// FieldError is a validation error for a single Parameters field.
+type FieldError struct {
+	// Field is the JSON name of the field, nested fields are separated by a dot.
+	Field string `json:"field"`
+	// Value is the rejected field value.
+	Value any `json:"value,omitempty"`
+	// Reason describes why the value was rejected.
+	Reason string `json:"reason"`
+}
+
+func (e *FieldError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s, got=%v", e.Field, e.Reason, e.Value)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidParameters
+}
+
+// FieldErrors holds the validation errors for each of the invalid Parameters fields,
+// the individual errors are retrieved with errors.As on a *FieldError.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	s := make([]string, 0, len(e))
+	for _, fe := range e {
+		s = append(s, fe.Error())
+	}
+
+	return fmt.Sprintf("%s: %s", ErrInvalidParameters, strings.Join(s, "; "))
+}
+
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, fe := range e {
+		errs = append(errs, fe)
+	}
+
+	return errs
+}
+
+// fieldValidator collects the field errors from validating Parameters.
+type fieldValidator struct {
+	errs FieldErrors
+}
+
+func (v *fieldValidator) add(field string, value any, reason string) {
+	v.errs = append(v.errs, &FieldError{Field: field, Value: value, Reason: reason})
+}
+
+func (v *fieldValidator) requireID(field string, id uuid.UUID) {
+	if id == uuid.Nil {
+		v.add(field, nil, "required")
+	}
+}
+
+func (v *fieldValidator) requireString(field, value string) {
+	if value == "" {
+		v.add(field, nil, "required")
+	}
+}
+
+func (v *fieldValidator) oneOf(field, value string, accepted ...string) {
+	switch {
+	case value == "":
+		v.add(field, nil, "required, expected one of: "+strings.Join(accepted, ", "))
+	case !slices.Contains(accepted, value):
+		v.add(field, value, "expected one of: "+strings.Join(accepted, ", "))
+	}
+}
+
+// err returns the collected FieldErrors, or nil when there are none.
+func (v *fieldValidator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return v.errs
+}
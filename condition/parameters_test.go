@@ -0,0 +1,148 @@
+package condition
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParametersValidate(t *testing.T) {
+	assetID := uuid.New()
+	configURL, _ := url.Parse("nats-obj://bios-configs/r6515.json")
+
+	testcases := []struct {
+		name   string
+		params Parameters
+		fields []string
+	}{
+		{
+			"bios set config valid",
+			NewBiosControlTaskParameters(assetID, SetConfig, configURL),
+			nil,
+		},
+		{
+			"bios set config without URL",
+			NewBiosControlTaskParameters(assetID, SetConfig, nil),
+			[]string{"bios_config_url"},
+		},
+		{
+			"bios reset without URL",
+			NewBiosControlTaskParameters(assetID, ResetConfig, nil),
+			nil,
+		},
+		{
+			"bios nil asset and unknown action",
+			NewBiosControlTaskParameters(uuid.Nil, "flash", nil),
+			[]string{"asset_id", "action"},
+		},
+		{
+			"server control power on",
+			NewServerControlTaskParameters(assetID, SetPowerState, "on", false, false),
+			nil,
+		},
+		{
+			"server control unknown action",
+			NewServerControlTaskParameters(assetID, "explode", "", false, false),
+			[]string{"action"},
+		},
+		{
+			"server control bad boot device",
+			NewServerControlTaskParameters(assetID, SetNextBootDevice, "tape", false, false),
+			[]string{"action_parameter"},
+		},
+		{
+			"server control validate firmware",
+			&ServerControlTaskParameters{
+				AssetID:                 assetID,
+				Action:                  ValidateFirmware,
+				ValidateFirmwareTimeout: time.Minute,
+				ValidateFirmwareID:      uuid.New(),
+			},
+			nil,
+		},
+		{
+			"server control validate firmware missing fields",
+			&ServerControlTaskParameters{AssetID: assetID, Action: ValidateFirmware},
+			[]string{"validate_firmware_timeout", "validate_firmware_id"},
+		},
+		{
+			"firmware install with set",
+			&FirmwareInstallTaskParameters{AssetID: assetID, FirmwareSetID: uuid.New()},
+			nil,
+		},
+		{
+			"firmware install without firmware",
+			&FirmwareInstallTaskParameters{AssetID: assetID},
+			[]string{"firmware_set_id"},
+		},
+		{
+			"firmware install incomplete firmware",
+			&FirmwareInstallTaskParameters{
+				AssetID:   assetID,
+				Firmwares: []Firmware{{URL: "https://example.com/bmc.bin", Component: "bmc"}},
+			},
+			[]string{"firmwares.0.version"},
+		},
+		{
+			"inventory default",
+			NewInventoryTaskParameters(assetID, OutofbandInventory, true, true),
+			nil,
+		},
+		{
+			"inventory empty method",
+			NewInventoryTaskParameters(assetID, "", true, true),
+			[]string{"inventory_method"},
+		},
+		{
+			"virtual media valid",
+			NewVirtualMediaTaskParameters(assetID, "https://example.com/boot.iso", MediaTypeISO, MountMethodURL),
+			nil,
+		},
+		{
+			"virtual media bad fields",
+			NewVirtualMediaTaskParameters(assetID, "boot.iso", "tape", ""),
+			[]string{"mount_method", "media_type", "image_uri"},
+		},
+		{
+			"broker acquire",
+			NewBrokerTaskParameters(assetID, AcquireServer, PurposeFirmwareInstall, "fw upgrade"),
+			nil,
+		},
+		{
+			"broker bad fields",
+			&BrokerTaskParameters{Action: "borrow", Purpose: PurposeFirmwareInstall},
+			[]string{"asset_id", "action"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.params.Validate()
+			if tc.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidParameters))
+
+			var fieldErrs FieldErrors
+			require.True(t, errors.As(err, &fieldErrs))
+
+			got := []string{}
+			for _, fe := range fieldErrs {
+				got = append(got, fe.Field)
+			}
+			assert.Equal(t, tc.fields, got)
+
+			var fe *FieldError
+			require.True(t, errors.As(err, &fe))
+			assert.Equal(t, tc.fields[0], fe.Field)
+		})
+	}
+}
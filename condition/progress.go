@@ -0,0 +1,215 @@
+package condition
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrProgressStep = errors.New("invalid Task progress step")
+)
+
+// ProgressStep is a named step of the work on a Task.
+//
+// nolint:govet // fieldalignment struct is easier to read in the current format
+type ProgressStep struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+
+	// Percent is the optional completion percentage of the step, reported by the worker.
+	Percent int `json:"percent,omitempty"`
+
+	// Error holds the cause of a failed step.
+	Error string `json:"error,omitempty"`
+
+	StartedAt   time.Time `json:"started_at,omitempty"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+}
+
+// Progress is the step based progress of the work on a Task.
+type Progress struct {
+	Steps []*ProgressStep `json:"steps"`
+
+	// EstimatedCompletion is the estimated completion time of the Task, derived from
+	// the elapsed time and the overall percentage complete.
+	EstimatedCompletion time.Time `json:"estimated_completion,omitempty"`
+}
+
+// Step returns the named step, or nil if there is none.
+func (p *Progress) Step(name string) *ProgressStep {
+	if p == nil {
+		return nil
+	}
+
+	for _, s := range p.Steps {
+		if s.Name == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// Current returns the step being worked on, or nil when no step is active.
+func (p *Progress) Current() *ProgressStep {
+	if p == nil {
+		return nil
+	}
+
+	for _, s := range p.Steps {
+		if s.State == Active {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// Percent returns the overall percentage complete, each step contributing equally.
+func (p *Progress) Percent() int {
+	if p == nil || len(p.Steps) == 0 {
+		return 0
+	}
+
+	total := 0
+
+	for _, s := range p.Steps {
+		switch {
+		case s.State == Succeeded:
+			total += 100
+		case s.Percent > 0:
+			total += min(s.Percent, 100)
+		}
+	}
+
+	return total / len(p.Steps)
+}
+
+// estimate updates the EstimatedCompletion from the time elapsed since the first step started.
+func (p *Progress) estimate(now time.Time) {
+	var started time.Time
+
+	for _, s := range p.Steps {
+		if !s.StartedAt.IsZero() && (started.IsZero() || s.StartedAt.Before(started)) {
+			started = s.StartedAt
+		}
+	}
+
+	percent := p.Percent()
+	if started.IsZero() || percent == 0 {
+		p.EstimatedCompletion = time.Time{}
+		return
+	}
+
+	elapsed := now.Sub(started)
+	p.EstimatedCompletion = started.Add(elapsed * 100 / time.Duration(percent))
+}
+
+// PlanSteps declares the steps of the work on the Task in the order they are run,
+// steps already declared are retained.
+func (t *Task[P, D]) PlanSteps(names ...string) {
+	if t.Progress == nil {
+		t.Progress = &Progress{}
+	}
+
+	for _, name := range names {
+		if t.Progress.Step(name) == nil {
+			t.Progress.Steps = append(t.Progress.Steps, &ProgressStep{Name: name, State: Pending})
+		}
+	}
+}
+
+// BeginStep marks the step as active, a step not declared with PlanSteps is appended.
+func (t *Task[P, D]) BeginStep(name string) error {
+	t.PlanSteps(name)
+
+	step := t.Progress.Step(name)
+	if StateIsComplete(step.State) {
+		return errors.Wrap(ErrProgressStep, fmt.Sprintf("step %s already %s", name, step.State))
+	}
+
+	now := time.Now()
+	step.State = Active
+	step.StartedAt = now
+
+	t.Status.AppendMsg(StatusMsg{Msg: "started", Step: name, Level: StatusLevelInfo, Timestamp: now})
+	t.Progress.estimate(now)
+
+	return nil
+}
+
+// AdvanceStep sets the percentage complete of the active step,
+// the message when not empty is appended to the Task StatusRecord.
+func (t *Task[P, D]) AdvanceStep(name string, percent int, msg string) error {
+	step, err := t.activeStep(name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	step.Percent = max(0, min(percent, 100))
+
+	if msg != "" {
+		t.Status.AppendMsg(StatusMsg{Msg: msg, Step: name, Level: StatusLevelInfo, Timestamp: now})
+	}
+
+	t.Progress.estimate(now)
+
+	return nil
+}
+
+// CompleteStep marks the active step as succeeded.
+func (t *Task[P, D]) CompleteStep(name string) error {
+	step, err := t.activeStep(name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	step.State = Succeeded
+	step.Percent = 100
+	step.CompletedAt = now
+
+	t.Status.AppendMsg(StatusMsg{Msg: "completed", Step: name, Level: StatusLevelInfo, Timestamp: now})
+	t.Progress.estimate(now)
+
+	return nil
+}
+
+// FailStep marks the active step as failed with the given cause.
+func (t *Task[P, D]) FailStep(name string, cause error) error {
+	step, err := t.activeStep(name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	step.State = Failed
+	step.CompletedAt = now
+
+	msg := "failed"
+	if cause != nil {
+		step.Error = cause.Error()
+		msg += ": " + cause.Error()
+	}
+
+	t.Status.AppendMsg(StatusMsg{Msg: msg, Step: name, Level: StatusLevelError, Timestamp: now})
+	t.Progress.estimate(now)
+
+	return nil
+}
+
+func (t *Task[P, D]) activeStep(name string) (*ProgressStep, error) {
+	step := t.Progress.Step(name)
+
+	switch {
+	case step == nil:
+		return nil, errors.Wrap(ErrProgressStep, "unknown step "+name)
+	case step.State != Active:
+		return nil, errors.Wrap(ErrProgressStep, fmt.Sprintf("step %s is %s, expected %s", name, step.State, Active))
+	}
+
+	return step, nil
+}
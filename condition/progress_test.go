@@ -0,0 +1,95 @@
+package condition
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskProgress(t *testing.T) {
+	task := NewTaskFromCondition(&Condition{ID: uuid.New(), Kind: FirmwareInstall, Target: uuid.New()})
+	assert.Nil(t, task.Progress)
+	assert.Zero(t, task.Progress.Percent())
+
+	task.PlanSteps("download", "install", "verify")
+	require.Len(t, task.Progress.Steps, 3)
+	assert.Equal(t, Pending, task.Progress.Step("install").State)
+
+	// steps must be started before they advance
+	err := task.AdvanceStep("download", 50, "")
+	assert.True(t, errors.Is(err, ErrProgressStep))
+	err = task.CompleteStep("reboot")
+	assert.True(t, errors.Is(err, ErrProgressStep))
+
+	require.NoError(t, task.BeginStep("download"))
+	assert.Equal(t, "download", task.Progress.Current().Name)
+	assert.False(t, task.Progress.Step("download").StartedAt.IsZero())
+	assert.True(t, task.Progress.EstimatedCompletion.IsZero())
+
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, task.AdvanceStep("download", 50, "downloaded 2 of 4 files"))
+	assert.Equal(t, 16, task.Progress.Percent())
+	assert.True(t, task.Progress.EstimatedCompletion.After(time.Now()))
+	assert.Equal(t, "downloaded 2 of 4 files", task.Status.Last())
+
+	require.NoError(t, task.CompleteStep("download"))
+	assert.Equal(t, 33, task.Progress.Percent())
+	assert.Nil(t, task.Progress.Current())
+
+	require.NoError(t, task.BeginStep("install"))
+	require.NoError(t, task.FailStep("install", errors.New("BMC unreachable")))
+	assert.Equal(t, Failed, task.Progress.Step("install").State)
+	assert.Equal(t, "BMC unreachable", task.Progress.Step("install").Error)
+
+	err = task.BeginStep("install")
+	assert.True(t, errors.Is(err, ErrProgressStep))
+
+	// steps not planned are appended
+	require.NoError(t, task.BeginStep("release"))
+	assert.Len(t, task.Progress.Steps, 4)
+
+	// the status record is kept in sync
+	last := task.Status.StatusMsgs[len(task.Status.StatusMsgs)-1]
+	assert.Equal(t, "release", last.Step)
+	failed := task.Status.StatusMsgs[len(task.Status.StatusMsgs)-2]
+	assert.Equal(t, "install", failed.Step)
+	assert.Equal(t, StatusLevelError, failed.Level)
+	assert.Equal(t, "failed: BMC unreachable", failed.Msg)
+
+	// progress round trips through the task message and updates
+	msg, err := task.Marshal()
+	require.NoError(t, err)
+
+	decoded, err := TaskFromMessage(msg)
+	require.NoError(t, err)
+	require.NotNil(t, decoded.Progress)
+	assert.Equal(t, task.Progress.Percent(), decoded.Progress.Percent())
+	assert.Equal(t, Failed, decoded.Progress.Step("install").State)
+
+	current := NewTaskFromCondition(&Condition{ID: task.ID, Kind: FirmwareInstall})
+	require.NoError(t, current.Update(task))
+	assert.Equal(t, task.Progress, current.Progress)
+}
+
+func TestTaskProgressCompatibility(t *testing.T) {
+	// tasks written before progress was added decode without it
+	task, err := TaskFromMessage(json.RawMessage(`{"task_version": "1.0", "kind": "inventory", "state": "active"}`))
+	require.NoError(t, err)
+	assert.Nil(t, task.Progress)
+
+	// and progress is omitted for tasks without it
+	msg, err := task.Marshal()
+	require.NoError(t, err)
+	assert.NotContains(t, string(msg), "progress")
+
+	// an update without progress retains the current progress
+	current := &Task[any, any]{Kind: Inventory}
+	current.PlanSteps("collect")
+	require.NoError(t, current.Update(&Task[any, any]{Kind: Inventory, State: Active}))
+	require.NotNil(t, current.Progress)
+}
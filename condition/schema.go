@@ -0,0 +1,339 @@
+package condition
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// JSONSchemaDialect is the JSON Schema draft the generated schemas conform to.
+	JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+	// schemaTag is the struct tag marking Parameters fields as required in the generated schema.
+	//
+	//	AssetID uuid.UUID `json:"asset_id" jsonschema:"required"`
+	schemaTag = "jsonschema"
+)
+
+var (
+	ErrSchemaInvalidJSON = errors.New("invalid JSON for schema validation")
+
+	enumsMu sync.RWMutex
+	enums   = map[reflect.Type][]any{}
+
+	typeUUID       = reflect.TypeOf(uuid.UUID{})
+	typeTime       = reflect.TypeOf(time.Time{})
+	typeRawMessage = reflect.TypeOf(json.RawMessage{})
+)
+
+func init() {
+	RegisterSchemaEnum(ResetConfig, SetConfig)
+	RegisterSchemaEnum(SetPowerState, GetPowerState, SetNextBootDevice, PxeBootPersistent, PowerCycleBMC, ValidateFirmware)
+	RegisterSchemaEnum(InbandInventory, OutofbandInventory)
+	RegisterSchemaEnum(MediaTypeFloppy, MediaTypeISO)
+	RegisterSchemaEnum(MountMethodUpload, MountMethodURL)
+	RegisterSchemaEnum(AcquireServer, ReleaseServer)
+	RegisterSchemaEnum(PurposeFirmwareInstall)
+}
+
+// RegisterSchemaEnum sets the accepted values for a string type in the generated schemas,
+// fields of the type are then limited to these values.
+func RegisterSchemaEnum[T ~string](values ...T) {
+	vals := make([]any, 0, len(values))
+	for _, v := range values {
+		vals = append(vals, string(v))
+	}
+
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+
+	enums[reflect.TypeOf(*new(T))] = vals
+}
+
+func schemaEnum(t reflect.Type) []any {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+
+	return enums[t]
+}
+
+// Schema is a JSON Schema document describing Condition Parameters.
+//
+// nolint:govet // fieldalignment struct is easier to read in the current format
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// ParametersSchema returns the JSON Schema for the Parameters of the Condition Kind.
+func ParametersSchema(kind Kind) (*Schema, error) {
+	params, err := NewParameters(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	s := SchemaFor(params)
+	s.Schema = JSONSchemaDialect
+	s.Title = string(kind)
+
+	return s, nil
+}
+
+// ValidateParametersJSON validates the raw Parameters JSON against the JSON Schema for the Condition Kind,
+// the FieldErrors returned identify each of the fields not conforming to the schema.
+func ValidateParametersJSON(kind Kind, raw json.RawMessage) error {
+	s, err := ParametersSchema(kind)
+	if err != nil {
+		return err
+	}
+
+	return s.Validate(raw)
+}
+
+// SchemaFor generates the JSON Schema for the JSON encoding of the given value.
+func SchemaFor(v any) *Schema {
+	return schemaForType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+// nolint:gocyclo // type mapping is cyclomatic
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case typeUUID:
+		return &Schema{Type: "string", Format: "uuid"}
+	case typeTime:
+		return &Schema{Type: "string", Format: "date-time"}
+	case typeRawMessage:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string", Enum: schemaEnum(t)}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoded as a base64 string
+			return &Schema{Type: "string"}
+		}
+
+		return &Schema{Type: "array", Items: schemaForType(t.Elem(), visiting)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{Type: "object"}
+		}
+
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		structProperties(t, s, visiting)
+
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+func structProperties(t reflect.Type, s *Schema, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		// embedded struct fields are promoted
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				structProperties(ft, s, visiting)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = schemaForType(field.Type, visiting)
+
+		if slices.Contains(strings.Split(field.Tag.Get(schemaTag), ","), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// Validate validates the JSON document against the schema, a FieldErrors value
+// is returned identifying each of the fields not conforming to the schema.
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return errors.Wrap(ErrSchemaInvalidJSON, err.Error())
+	}
+
+	fv := &fieldValidator{}
+	s.validate("", v, fv)
+
+	return fv.err()
+}
+
+// nolint:gocyclo // schema validation is cyclomatic
+func (s *Schema) validate(path string, v any, fv *fieldValidator) {
+	field := path
+	if field == "" {
+		field = "."
+	}
+
+	if v == nil {
+		// null is accepted for optional fields, matching the JSON decoding of Go types
+		return
+	}
+
+	switch s.Type {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fv.add(field, v, "expected a string")
+			return
+		}
+
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, any(str)) {
+			fv.add(field, str, "expected one of: "+enumString(s.Enum))
+		}
+
+		if s.Format == "uuid" {
+			if _, err := uuid.Parse(str); err != nil {
+				fv.add(field, str, "expected a UUID")
+			}
+		}
+
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				fv.add(field, str, "expected an RFC3339 date-time")
+			}
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fv.add(field, v, "expected a boolean")
+		}
+
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			fv.add(field, v, "expected an integer")
+			return
+		}
+
+		if _, err := n.Int64(); err != nil {
+			fv.add(field, v, "expected an integer")
+		}
+
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			fv.add(field, v, "expected a number")
+		}
+
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			fv.add(field, v, "expected an array")
+			return
+		}
+
+		if s.Items != nil {
+			for idx, item := range items {
+				s.Items.validate(joinPath(path, fmt.Sprint(idx)), item, fv)
+			}
+		}
+
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fv.add(field, v, "expected an object")
+			return
+		}
+
+		for _, name := range s.Required {
+			if val, exists := obj[name]; !exists || val == nil {
+				fv.add(joinPath(path, name), nil, "required")
+			}
+		}
+
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			switch {
+			case s.Properties[k] != nil:
+				s.Properties[k].validate(joinPath(path, k), obj[k], fv)
+			case s.AdditionalProperties != nil:
+				s.AdditionalProperties.validate(joinPath(path, k), obj[k], fv)
+			}
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func enumString(values []any) string {
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		s = append(s, fmt.Sprint(v))
+	}
+
+	return strings.Join(s, ", ")
+}
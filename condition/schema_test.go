@@ -0,0 +1,118 @@
+package condition
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParametersSchema(t *testing.T) {
+	s, err := ParametersSchema(ServerControl)
+	require.NoError(t, err)
+
+	assert.Equal(t, JSONSchemaDialect, s.Schema)
+	assert.Equal(t, string(ServerControl), s.Title)
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, []string{"asset_id", "action"}, s.Required)
+	assert.Equal(t, &Schema{Type: "string", Format: "uuid"}, s.Properties["asset_id"])
+	assert.Equal(t, "integer", s.Properties["validate_firmware_timeout"].Type)
+	assert.Contains(t, s.Properties["action"].Enum, string(PowerCycleBMC))
+
+	// every builtin kind has a schema
+	for _, kind := range RegisteredKinds() {
+		s, err := ParametersSchema(kind)
+		require.NoError(t, err, kind)
+
+		_, err = json.Marshal(s)
+		require.NoError(t, err, kind)
+	}
+
+	fw, err := ParametersSchema(FirmwareInstall)
+	require.NoError(t, err)
+	assert.Equal(t, "array", fw.Properties["firmwares"].Type)
+	assert.Equal(t, "string", fw.Properties["firmwares"].Items.Properties["URL"].Type)
+
+	inv, err := ParametersSchema(Inventory)
+	require.NoError(t, err)
+	assert.Equal(t, []any{string(InbandInventory), string(OutofbandInventory)}, inv.Properties["inventory_method"].Enum)
+
+	_, err = ParametersSchema("reticulateSplines")
+	assert.True(t, errors.Is(err, ErrUnknownKind))
+}
+
+func TestValidateParametersJSON(t *testing.T) {
+	assetID := uuid.New()
+
+	testcases := []struct {
+		name   string
+		kind   Kind
+		raw    string
+		fields []string
+	}{
+		{
+			"inventory valid",
+			Inventory,
+			string(MustDefaultInventoryJSON(assetID)),
+			nil,
+		},
+		{
+			"inventory bad method and asset",
+			Inventory,
+			`{"asset_id": "not-a-uuid", "inventory_method": "sideband", "collect_bios_cfg": "yes"}`,
+			[]string{"asset_id", "collect_bios_cfg", "inventory_method"},
+		},
+		{
+			"server control missing required",
+			ServerControl,
+			`{"action_parameter": "on"}`,
+			[]string{"asset_id", "action"},
+		},
+		{
+			"firmware install nested fields",
+			FirmwareInstall,
+			`{"asset_id": "` + assetID.String() + `", "firmwares": [{"URL": 1, "models": ["r6515", 2]}]}`,
+			[]string{"firmwares.0.URL", "firmwares.0.models.1"},
+		},
+		{
+			"broker bad action",
+			BrokerAcquireServer,
+			`{"asset_id": "` + assetID.String() + `", "action": "borrow", "action_purpose": "firmwareInstall"}`,
+			[]string{"action"},
+		},
+		{
+			"not an object",
+			VirtualMediaMount,
+			`[]`,
+			[]string{"."},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParametersJSON(tc.kind, json.RawMessage(tc.raw))
+			if tc.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidParameters))
+
+			var fieldErrs FieldErrors
+			require.True(t, errors.As(err, &fieldErrs))
+
+			got := []string{}
+			for _, fe := range fieldErrs {
+				got = append(got, fe.Field)
+			}
+			assert.Equal(t, tc.fields, got)
+		})
+	}
+
+	err := ValidateParametersJSON(Inventory, json.RawMessage(`{`))
+	assert.True(t, errors.Is(err, ErrSchemaInvalidJSON))
+}
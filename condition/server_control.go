@@ -62,12 +62,12 @@ type ServerControlTaskParameters struct {
 	// Identifier for the Asset in the Asset store.
 	//
 	// Required: true
-	AssetID uuid.UUID `json:"asset_id"`
+	AssetID uuid.UUID `json:"asset_id" jsonschema:"required"`
 
 	// The server control action to be performed
 	//
 	// Required: true
-	Action ServerControlAction `json:"action"`
+	Action ServerControlAction `json:"action" jsonschema:"required"`
 
 	// Action parameter to the ServerControlAction
 	//
@@ -118,3 +118,38 @@ func NewServerControlTaskParameters(assetID uuid.UUID, action ServerControlActio
 		SetNextBootDeviceEFI:        efiBoot,
 	}
 }
+
+// Validate implements the Parameters interface.
+func (p *ServerControlTaskParameters) Validate() error {
+	v := &fieldValidator{}
+	v.requireID("asset_id", p.AssetID)
+	v.oneOf(
+		"action",
+		string(p.Action),
+		string(SetPowerState),
+		string(GetPowerState),
+		string(SetNextBootDevice),
+		string(PxeBootPersistent),
+		string(PowerCycleBMC),
+		string(ValidateFirmware),
+	)
+
+	switch p.Action {
+	case SetPowerState:
+		v.oneOf("action_parameter", p.ActionParameter, "on", "off", "cycle", "reset", "soft")
+	case SetNextBootDevice:
+		v.oneOf(
+			"action_parameter",
+			p.ActionParameter,
+			"bios", "cdrom", "diag", "floppy", "disk", "none", "pxe", "remote_drive", "sd_card", "usb", "utilities",
+		)
+	case ValidateFirmware:
+		if p.ValidateFirmwareTimeout <= 0 {
+			v.add("validate_firmware_timeout", p.ValidateFirmwareTimeout, "required for action "+string(ValidateFirmware))
+		}
+
+		v.requireID("validate_firmware_id", p.ValidateFirmwareID)
+	}
+
+	return v.err()
+}
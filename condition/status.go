@@ -93,19 +93,64 @@ const (
 	Complete                // task is done
 	Orphaned                // the worker that started this task doesn't exist anymore
 	Indeterminate           // we got an error in the process of making the check
+	Stale                   // the worker that started this task is around, but has not updated its status recently
 )
 
+type checkConfig struct {
+	registry       *registry.Registry
+	staleThreshold time.Duration
+	cleanupOrphans bool
+}
+
+// CheckOption sets optional parameters for CheckConditionInProgress.
+type CheckOption func(c *checkConfig)
+
+// WithRegistry sets the controller registry used to look up the worker,
+// the registry package default is used otherwise.
+func WithRegistry(reg *registry.Registry) CheckOption {
+	return func(c *checkConfig) {
+		c.registry = reg
+	}
+}
+
+// WithStaleThreshold sets the period after which a status not updated by a live worker is Stale,
+// the default is StatusStaleThreshold, a zero value disables the check.
+func WithStaleThreshold(d time.Duration) CheckOption {
+	return func(c *checkConfig) {
+		c.staleThreshold = d
+	}
+}
+
+// WithoutOrphanCleanup leaves the status of an Orphaned condition in place,
+// by default it is deleted to let the condition be restarted.
+func WithoutOrphanCleanup() CheckOption {
+	return func(c *checkConfig) {
+		c.cleanupOrphans = false
+	}
+}
+
 // CheckConditionInProgress returns the status of the task from the KV store
 //
 //nolint:gocyclo // status checks are cyclomatic
-func CheckConditionInProgress(conditionID, facilityCode, kvBucket string, js nats.JetStreamContext) (TaskState, error) {
+func CheckConditionInProgress(conditionID, facilityCode, kvBucket string, js nats.JetStreamContext,
+	opts ...CheckOption) (TaskState, error) {
+	cfg := &checkConfig{
+		registry:       registry.Default(),
+		staleThreshold: StatusStaleThreshold,
+		cleanupOrphans: true,
+	}
+
+	for _, o := range opts {
+		o(cfg)
+	}
+
 	handle, err := js.KeyValue(kvBucket)
 	if err != nil {
 		errKV := errors.Wrap(err, "bind to status KV bucket for condition lookup failed")
 		return Indeterminate, newErrQueryStatus(errKV, kvBucket, "", "")
 	}
 
-	lookupKey := fmt.Sprintf("%s.%s", facilityCode, conditionID)
+	lookupKey := StatusValueKVKey(facilityCode, conditionID)
 	entry, err := handle.Get(lookupKey)
 	switch {
 	case errors.Is(err, nats.ErrKeyNotFound):
@@ -120,8 +165,8 @@ func CheckConditionInProgress(conditionID, facilityCode, kvBucket string, js nat
 	}
 
 	// we have an status entry for this condition, is is complete?
-	sv := StatusValue{}
-	if errJSON := json.Unmarshal(entry.Value(), &sv); errJSON != nil {
+	sv, errJSON := DecodeStatusValue(entry.Value())
+	if errJSON != nil {
 		errJSON = errors.Wrap(errJSON, "unable to construct a sane status for condition")
 		return Indeterminate, newErrQueryStatus(errJSON, kvBucket, lookupKey, "")
 	}
@@ -137,13 +182,16 @@ func CheckConditionInProgress(conditionID, facilityCode, kvBucket string, js nat
 		return Indeterminate, newErrQueryStatus(errWorker, kvBucket, lookupKey, sv.WorkerID)
 	}
 
-	_, err = registry.LastContact(controllerID)
+	_, err = cfg.registry.LastContact(controllerID)
 	switch {
 	case errors.Is(err, nats.ErrKeyNotFound):
 		// the data for this worker aged-out, it's no longer active
 		// XXX: the most conservative thing to do here is to return
 		// indeterminate but most times this will indicate that the
 		// worker crashed/restarted and this task should be restarted.
+		if !cfg.cleanupOrphans {
+			return Orphaned, nil
+		}
 
 		// We're going to restart this condition when we return from
 		// this function. Use the KV handle we have to delete the
@@ -157,6 +205,11 @@ func CheckConditionInProgress(conditionID, facilityCode, kvBucket string, js nat
 		return Orphaned, nil
 
 	case err == nil:
+		// the worker is alive, but may be stuck
+		if cfg.staleThreshold > 0 && !sv.UpdatedAt.IsZero() && time.Since(sv.UpdatedAt) > cfg.staleThreshold {
+			return Stale, nil
+		}
+
 		return InProgress, nil
 
 	default:
@@ -165,8 +218,46 @@ func CheckConditionInProgress(conditionID, facilityCode, kvBucket string, js nat
 	}
 }
 
-func NewTaskStatusRecord(s string) StatusRecord {
+const (
+	// DefaultStatusRecordRetention is the number of messages a StatusRecord retains by default.
+	DefaultStatusRecordRetention = 5
+)
+
+// StatusLevel is the severity of a StatusMsg.
+type StatusLevel string
+
+const (
+	StatusLevelDebug StatusLevel = "debug"
+	StatusLevelInfo  StatusLevel = "info"
+	StatusLevelWarn  StatusLevel = "warn"
+	StatusLevelError StatusLevel = "error"
+)
+
+// StatusRecordOption sets optional parameters on a StatusRecord.
+type StatusRecordOption func(sr *StatusRecord)
+
+// WithRetention sets the number of messages retained by the StatusRecord,
+// the oldest messages are dropped beyond this number.
+func WithRetention(n int) StatusRecordOption {
+	return func(sr *StatusRecord) {
+		sr.MaxRecords = n
+	}
+}
+
+// WithCollapse counts a message repeating the last message in the StatusRecord,
+// instead of dropping it.
+func WithCollapse() StatusRecordOption {
+	return func(sr *StatusRecord) {
+		sr.Collapse = true
+	}
+}
+
+func NewTaskStatusRecord(s string, opts ...StatusRecordOption) StatusRecord {
 	sr := StatusRecord{}
+	for _, o := range opts {
+		o(&sr)
+	}
+
 	if s == "" {
 		return sr
 	}
@@ -181,34 +272,92 @@ var (
 )
 
 // StatusRecord holds status information for a Condition
+//
+// The retention and collapse settings are serialized along with the messages,
+// records written without them use the defaults.
 type StatusRecord struct {
 	StatusMsgs []StatusMsg `json:"records"`
+
+	// MaxRecords is the number of messages retained, DefaultStatusRecordRetention is used when unset.
+	MaxRecords int `json:"maxRecords,omitempty"`
+
+	// Collapse when set counts messages repeating the last message, by default these are dropped.
+	Collapse bool `json:"collapse,omitempty"`
 }
 
 // StatusMsg is a single record within the StatusRecord
+//
+// nolint:govet // fieldalignment struct is easier to read in the current format
 type StatusMsg struct {
 	Timestamp time.Time `json:"ts,omitempty"`
 	Msg       string    `json:"msg,omitempty"`
+
+	// Level is the severity of the message, an unset level is considered to be info.
+	Level StatusLevel `json:"level,omitempty"`
+
+	// Step identifies the component or step of the work the message is about.
+	Step string `json:"step,omitempty"`
+
+	// Fields are structured key/values for the message.
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// Count is the number of times the message repeated, when the StatusRecord collapses repeats.
+	Count int `json:"count,omitempty"`
+}
+
+// same returns true when the message text, level and step are equal.
+func (m *StatusMsg) same(o *StatusMsg) bool {
+	return m.Msg == o.Msg && m.Level == o.Level && m.Step == o.Step
 }
 
 func (sr *StatusRecord) Append(s string) {
-	if s == "" {
+	sr.AppendMsg(StatusMsg{Msg: s})
+}
+
+// AppendMsg appends the message to the record, the Timestamp is set if unset.
+//
+// A message equal to one already retained is dropped, unless the record collapses repeats,
+// in which case a repeat of the last message increments its Count.
+func (sr *StatusRecord) AppendMsg(m StatusMsg) {
+	if m.Msg == "" {
 		return
 	}
 
-	for _, r := range sr.StatusMsgs {
-		if r.Msg == s {
+	if m.Timestamp.IsZero() {
+		m.Timestamp = time.Now()
+	}
+
+	if sr.Collapse {
+		if n := len(sr.StatusMsgs); n > 0 && sr.StatusMsgs[n-1].same(&m) {
+			last := &sr.StatusMsgs[n-1]
+			if last.Count == 0 {
+				last.Count = 1
+			}
+
+			last.Count++
+			last.Timestamp = m.Timestamp
+			last.Fields = m.Fields
+
 			return
 		}
+	} else {
+		for idx := range sr.StatusMsgs {
+			if sr.StatusMsgs[idx].same(&m) {
+				return
+			}
+		}
 	}
 
-	if len(sr.StatusMsgs) > 4 {
-		sr.StatusMsgs = sr.StatusMsgs[1:]
-	}
+	sr.StatusMsgs = append(sr.StatusMsgs, m)
 
-	n := StatusMsg{Timestamp: time.Now(), Msg: s}
+	retain := sr.MaxRecords
+	if retain <= 0 {
+		retain = DefaultStatusRecordRetention
+	}
 
-	sr.StatusMsgs = append(sr.StatusMsgs, n)
+	if len(sr.StatusMsgs) > retain {
+		sr.StatusMsgs = sr.StatusMsgs[len(sr.StatusMsgs)-retain:]
+	}
 }
 
 func (sr *StatusRecord) Last() string {
@@ -219,10 +368,12 @@ func (sr *StatusRecord) Last() string {
 	return sr.StatusMsgs[len(sr.StatusMsgs)-1].Msg
 }
 
+// Update replaces the text of the messages matching currentMsg and refreshes their Timestamp.
 func (sr *StatusRecord) Update(currentMsg, newMsg string) {
 	for idx, r := range sr.StatusMsgs {
 		if r.Msg == currentMsg {
 			sr.StatusMsgs[idx].Msg = newMsg
+			sr.StatusMsgs[idx].Timestamp = time.Now()
 		}
 	}
 }
@@ -0,0 +1,223 @@
+package condition
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-automata/rivets/events/pkg/kv"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid Condition state transition")
+)
+
+// statusValueCodec decodes StatusValues upgrading them from older versions, as with DecodeStatusValue.
+type statusValueCodec struct {
+	kv.JSONCodec
+}
+
+func (c statusValueCodec) Unmarshal(data []byte, v any) error {
+	migrated, err := statusValueMigrations.migrate(data)
+	if err != nil {
+		return err
+	}
+
+	return c.JSONCodec.Unmarshal(migrated, v)
+}
+
+// StatusStore reads and writes the Condition StatusValues in a KV bucket, keyed by StatusValueKVKey.
+type StatusStore struct {
+	store    *kv.Typed[StatusValue]
+	workerID string
+	audit    *AuditLog
+}
+
+type StatusStoreOption func(s *StatusStore)
+
+// WithAuditLog records each of the StatusValues published in the AuditLog.
+func WithAuditLog(a *AuditLog) StatusStoreOption {
+	return func(s *StatusStore) {
+		s.audit = a
+	}
+}
+
+// NewStatusStore returns a StatusStore on the KV bucket, the workerID is set on the StatusValues
+// published and may be left empty by readers.
+func NewStatusStore(handle nats.KeyValue, workerID string, opts ...StatusStoreOption) *StatusStore {
+	s := &StatusStore{
+		store:    kv.NewTyped[StatusValue](handle, kv.WithCodec[StatusValue](statusValueCodec{})),
+		workerID: workerID,
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	return s
+}
+
+// KeyValue exposes the underlying nats.KeyValue
+func (s *StatusStore) KeyValue() nats.KeyValue {
+	return s.store.KeyValue()
+}
+
+// Publish writes the StatusValue for the Condition, stamping its UpdatedAt, MsgVersion and WorkerID.
+//
+// The write is rejected with ErrInvalidStatusTransition when the State transition from the current
+// StatusValue is not valid, the CreatedAt of the current StatusValue is retained.
+//
+// When an AuditLog is set the change is recorded once written, an error recording it wraps ErrAuditRecord.
+func (s *StatusStore) Publish(facilityCode, conditionID string, sv *StatusValue) error {
+	next := State(sv.State)
+	if !StateIsValid(next) {
+		return errors.Wrap(ErrInvalidStatusTransition, "unknown state: "+sv.State)
+	}
+
+	key := StatusValueKVKey(facilityCode, conditionID)
+
+	var previous State
+
+	entry, err := s.store.Update(key, func(current *StatusValue) error {
+		now := time.Now()
+		previous = State(current.State)
+
+		switch {
+		case current.State == "":
+			// new status value
+			if sv.CreatedAt.IsZero() {
+				sv.CreatedAt = now
+			}
+		case !State(current.State).TransitionValid(next):
+			return errors.Wrap(ErrInvalidStatusTransition, fmt.Sprintf("%s -> %s, key=%s", current.State, next, key))
+		default:
+			sv.CreatedAt = current.CreatedAt
+		}
+
+		sv.UpdatedAt = now
+		sv.MsgVersion = StatusValueVersion
+
+		if s.workerID != "" {
+			sv.WorkerID = s.workerID
+		}
+
+		*current = *sv
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*sv = entry.Value
+
+	if s.audit == nil {
+		return nil
+	}
+
+	return s.record(facilityCode, conditionID, previous, sv)
+}
+
+func (s *StatusStore) record(facilityCode, conditionID string, previous State, sv *StatusValue) error {
+	id, err := uuid.Parse(conditionID)
+	if err != nil {
+		return errors.Wrap(ErrAuditRecord, "condition ID: "+err.Error())
+	}
+
+	event := AuditStatusChange
+	if previous != State(sv.State) {
+		event = AuditStateTransition
+	}
+
+	return s.audit.Record(context.Background(), &AuditEntry{
+		ConditionID:  id,
+		FacilityCode: facilityCode,
+		Event:        event,
+		FromState:    previous,
+		ToState:      State(sv.State),
+		Status:       sv.Status,
+		WorkerID:     sv.WorkerID,
+		TraceID:      sv.TraceID,
+		SpanID:       sv.SpanID,
+		Timestamp:    sv.UpdatedAt,
+	})
+}
+
+// Get returns the StatusValue for the Condition, nats.ErrKeyNotFound is returned if there is none.
+func (s *StatusStore) Get(facilityCode, conditionID string) (*StatusValue, error) {
+	entry, err := s.store.Get(StatusValueKVKey(facilityCode, conditionID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry.Value, nil
+}
+
+// List returns the StatusValues in the facility keyed by the Condition ID.
+func (s *StatusStore) List(ctx context.Context, facilityCode string) (map[string]*StatusValue, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, err := s.store.Watch(ctx, []string{StatusValueKVKey(facilityCode, "*")}, kv.WatchIgnoreDeletes())
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]*StatusValue{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case ev, ok := <-updates:
+			if !ok {
+				return nil, ctx.Err()
+			}
+
+			if ev.Op == kv.OpSynced {
+				return values, nil
+			}
+
+			if ev.Err != nil {
+				continue
+			}
+
+			sv := ev.Value
+			values[strings.TrimPrefix(ev.Key, facilityCode+".")] = &sv
+		}
+	}
+}
+
+// Watch sends the changes to the StatusValue of the Condition in any facility on the returned channel,
+// starting with the current value, until the context is canceled.
+func (s *StatusStore) Watch(ctx context.Context, conditionID string) (<-chan kv.WatchEvent[StatusValue], error) {
+	return s.store.Watch(ctx, []string{StatusValueKVKey("*", conditionID)})
+}
+
+// WaitForCompletion blocks until the StatusValue of the Condition is in a final state and returns the state,
+// the context error is returned if it is canceled before then.
+func (s *StatusStore) WaitForCompletion(ctx context.Context, conditionID string) (State, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, err := s.Watch(ctx, conditionID)
+	if err != nil {
+		return "", err
+	}
+
+	for ev := range updates {
+		if ev.Op != kv.OpPut || ev.Err != nil {
+			continue
+		}
+
+		if state := State(ev.Value.State); StateIsComplete(state) {
+			return state, nil
+		}
+	}
+
+	return "", ctx.Err()
+}
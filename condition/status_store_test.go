@@ -0,0 +1,115 @@
+package condition
+
+//nolint:all // test file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusStore(t *testing.T) {
+	srv := startJetStreamServer(t)
+	defer shutdownJetStream(t, srv)
+	_, js := jetStreamContext(t, srv)
+
+	handle, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: string(FirmwareInstall)})
+	require.NoError(t, err)
+
+	worker := NewStatusStore(handle, "flasher-abc")
+	reader := NewStatusStore(handle, "")
+
+	ctx := context.Background()
+	conditionID := uuid.New().String()
+
+	_, err = reader.Get("fac13", conditionID)
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+
+	// wait on the condition before its status is published
+	type result struct {
+		state State
+		err   error
+	}
+	completed := make(chan result)
+	go func() {
+		state, err := reader.WaitForCompletion(ctx, conditionID)
+		completed <- result{state, err}
+	}()
+
+	sv := &StatusValue{Target: uuid.NewString(), State: string(Pending), Status: []byte(`{"msg":"queued"}`)}
+	require.NoError(t, worker.Publish("fac13", conditionID, sv))
+	assert.Equal(t, "flasher-abc", sv.WorkerID)
+	assert.Equal(t, StatusValueVersion, sv.MsgVersion)
+	assert.False(t, sv.UpdatedAt.IsZero())
+	createdAt := sv.CreatedAt
+
+	got, err := reader.Get("fac13", conditionID)
+	require.NoError(t, err)
+	assert.Equal(t, string(Pending), got.State)
+	assert.Equal(t, "flasher-abc", got.WorkerID)
+
+	active := &StatusValue{Target: sv.Target, State: string(Active), Status: []byte(`{"msg":"installing"}`)}
+	require.NoError(t, worker.Publish("fac13", conditionID, active))
+	assert.True(t, createdAt.Equal(active.CreatedAt))
+
+	// invalid transitions are rejected
+	err = worker.Publish("fac13", conditionID, &StatusValue{State: string(Pending)})
+	assert.True(t, errors.Is(err, ErrInvalidStatusTransition))
+
+	err = worker.Publish("fac13", conditionID, &StatusValue{State: "exploded"})
+	assert.True(t, errors.Is(err, ErrInvalidStatusTransition))
+
+	select {
+	case <-completed:
+		t.Fatal("condition not complete")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, worker.Publish("fac13", conditionID, &StatusValue{State: string(Succeeded)}))
+
+	select {
+	case res := <-completed:
+		require.NoError(t, res.err)
+		assert.Equal(t, Succeeded, res.state)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for completion")
+	}
+
+	// the final state is not changed
+	err = worker.Publish("fac13", conditionID, &StatusValue{State: string(Failed)})
+	assert.True(t, errors.Is(err, ErrInvalidStatusTransition))
+
+	// a completed condition returns right away
+	state, err := reader.WaitForCompletion(ctx, conditionID)
+	require.NoError(t, err)
+	assert.Equal(t, Succeeded, state)
+
+	// waiting is bounded by the context
+	waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = reader.WaitForCompletion(waitCtx, uuid.NewString())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// list by facility
+	other := uuid.New().String()
+	require.NoError(t, worker.Publish("fac13", other, &StatusValue{State: string(Active)}))
+	require.NoError(t, worker.Publish("fac42", uuid.NewString(), &StatusValue{State: string(Active)}))
+
+	// values written before versioning are read
+	legacy := uuid.New().String()
+	_, err = handle.Put(StatusValueKVKey("fac13", legacy), []byte(`{"worker":"alloy-abc","state":"active"}`))
+	require.NoError(t, err)
+
+	values, err := reader.List(ctx, "fac13")
+	require.NoError(t, err)
+	require.Len(t, values, 3)
+	assert.Equal(t, string(Succeeded), values[conditionID].State)
+	assert.Equal(t, string(Active), values[other].State)
+	assert.Equal(t, "alloy-abc", values[legacy].WorkerID)
+}
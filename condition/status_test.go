@@ -3,6 +3,7 @@ package condition
 //nolint:all // test file
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"testing"
@@ -282,3 +283,166 @@ func TestTaskStatusRecord(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckConditionInProgressOptions(t *testing.T) {
+	srv := startJetStreamServer(t)
+	defer shutdownJetStream(t, srv)
+	_, js := jetStreamContext(t, srv)
+
+	statusKV, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: "statusBucket"})
+	require.NoError(t, err)
+
+	// a registry handle in place of the package default
+	registryKV, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: "controllerRegistry"})
+	require.NoError(t, err)
+	reg := registry.NewFromKV(registryKV)
+
+	workerID := registry.GetID("workerB")
+	require.NoError(t, reg.Register(workerID))
+
+	facilityCode := "test1"
+	conditionID := uuid.New().String()
+	key := StatusValueKVKey(facilityCode, conditionID)
+
+	putStatus := func(updatedAt time.Time) {
+		sv := &StatusValue{State: string(Active), WorkerID: workerID.String(), UpdatedAt: updatedAt}
+		_, err := statusKV.Put(key, sv.MustBytes())
+		require.NoError(t, err)
+	}
+
+	// recently updated
+	putStatus(time.Now())
+	state, err := CheckConditionInProgress(conditionID, facilityCode, "statusBucket", js, WithRegistry(reg))
+	require.NoError(t, err)
+	assert.Equal(t, InProgress, state)
+
+	// alive worker, but the status was not updated within the threshold
+	putStatus(time.Now().Add(-time.Hour))
+	state, err = CheckConditionInProgress(conditionID, facilityCode, "statusBucket", js,
+		WithRegistry(reg), WithStaleThreshold(30*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, Stale, state)
+
+	// the default threshold
+	state, err = CheckConditionInProgress(conditionID, facilityCode, "statusBucket", js, WithRegistry(reg))
+	require.NoError(t, err)
+	assert.Equal(t, InProgress, state)
+
+	putStatus(time.Now().Add(-StatusStaleThreshold - time.Minute))
+	state, err = CheckConditionInProgress(conditionID, facilityCode, "statusBucket", js, WithRegistry(reg))
+	require.NoError(t, err)
+	assert.Equal(t, Stale, state)
+
+	// staleness checks disabled
+	state, err = CheckConditionInProgress(conditionID, facilityCode, "statusBucket", js,
+		WithRegistry(reg), WithStaleThreshold(0))
+	require.NoError(t, err)
+	assert.Equal(t, InProgress, state)
+
+	// orphaned without cleanup keeps the status
+	require.NoError(t, reg.Deregister(workerID))
+	state, err = CheckConditionInProgress(conditionID, facilityCode, "statusBucket", js,
+		WithRegistry(reg), WithoutOrphanCleanup())
+	require.NoError(t, err)
+	assert.Equal(t, Orphaned, state)
+
+	_, err = statusKV.Get(key)
+	require.NoError(t, err)
+
+	// orphaned with the default cleanup removes the status
+	state, err = CheckConditionInProgress(conditionID, facilityCode, "statusBucket", js, WithRegistry(reg))
+	require.NoError(t, err)
+	assert.Equal(t, Orphaned, state)
+
+	_, err = statusKV.Get(key)
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+}
+
+func TestStatusRecordLevelsAndRetention(t *testing.T) {
+	// retention
+	sr := NewTaskStatusRecord("a", WithRetention(3))
+	for _, s := range []string{"b", "c", "d"} {
+		sr.Append(s)
+	}
+	assert.Equal(t, []string{"b", "c", "d"}, statusMsgs(sr))
+
+	// levels, steps and fields
+	sr = NewTaskStatusRecord("")
+	sr.AppendMsg(StatusMsg{Msg: "install failed", Level: StatusLevelError, Step: "bmc", Fields: map[string]string{"version": "5.10"}})
+	sr.AppendMsg(StatusMsg{Msg: "install failed", Level: StatusLevelError, Step: "bios"})
+	require.Len(t, sr.StatusMsgs, 2, "messages for different steps are not duplicates")
+	assert.Equal(t, StatusLevelError, sr.StatusMsgs[0].Level)
+	assert.Equal(t, "5.10", sr.StatusMsgs[0].Fields["version"])
+	assert.False(t, sr.StatusMsgs[1].Timestamp.IsZero())
+
+	// collapse counts repeats of the last message
+	sr = NewTaskStatusRecord("polling task", WithCollapse())
+	first := sr.StatusMsgs[0].Timestamp
+	time.Sleep(time.Millisecond)
+	sr.Append("polling task")
+	sr.Append("polling task")
+	sr.Append("task complete")
+	sr.Append("polling task")
+	assert.Equal(t, []string{"polling task", "task complete", "polling task"}, statusMsgs(sr))
+	assert.Equal(t, 3, sr.StatusMsgs[0].Count)
+	assert.True(t, sr.StatusMsgs[0].Timestamp.After(first))
+	assert.Zero(t, sr.StatusMsgs[1].Count)
+
+	// update refreshes the timestamp
+	before := sr.StatusMsgs[1].Timestamp
+	time.Sleep(time.Millisecond)
+	sr.Update("task complete", "task completed")
+	assert.Equal(t, "task completed", sr.StatusMsgs[1].Msg)
+	assert.True(t, sr.StatusMsgs[1].Timestamp.After(before))
+}
+
+func TestStatusRecordFormats(t *testing.T) {
+	// the format written before levels, fields and retention were added
+	old := []byte(`{"records":[{"ts":"2024-01-02T03:04:05Z","msg":"a"},{"ts":"2024-01-02T03:04:06Z","msg":"b"}]}`)
+
+	sr, err := StatusRecordFromMessage(old)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, statusMsgs(*sr))
+	assert.Equal(t, StatusLevel(""), sr.StatusMsgs[0].Level)
+
+	for _, s := range []string{"c", "d", "e", "f"} {
+		sr.Append(s)
+	}
+	assert.Len(t, sr.StatusMsgs, DefaultStatusRecordRetention)
+
+	// the new format round trips
+	sr = &StatusRecord{MaxRecords: 10, Collapse: true}
+	sr.AppendMsg(StatusMsg{Msg: "flashing", Level: StatusLevelWarn, Step: "nic", Fields: map[string]string{"slot": "2"}})
+	sr.Append("flashing")
+
+	b, err := sr.Marshal()
+	require.NoError(t, err)
+
+	got, err := StatusRecordFromMessage(b)
+	require.NoError(t, err)
+	assert.Equal(t, 10, got.MaxRecords)
+	assert.True(t, got.Collapse)
+	assert.Equal(t, "nic", got.StatusMsgs[0].Step)
+	assert.Equal(t, "2", got.StatusMsgs[0].Fields["slot"])
+	assert.Equal(t, StatusLevelWarn, got.StatusMsgs[0].Level)
+
+	// the new format is readable with the fields of the old format
+	var legacy struct {
+		StatusMsgs []struct {
+			Timestamp time.Time `json:"ts,omitempty"`
+			Msg       string    `json:"msg,omitempty"`
+		} `json:"records"`
+	}
+	require.NoError(t, json.Unmarshal(b, &legacy))
+	require.Len(t, legacy.StatusMsgs, 2)
+	assert.Equal(t, "flashing", legacy.StatusMsgs[0].Msg)
+}
+
+func statusMsgs(sr StatusRecord) []string {
+	msgs := []string{}
+	for _, m := range sr.StatusMsgs {
+		msgs = append(msgs, m.Msg)
+	}
+
+	return msgs
+}
@@ -1,6 +1,7 @@
 package condition
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -13,6 +14,11 @@ import (
 var (
 	errInvalidTaskJSON = errors.New("invalid Task JSON")
 	errTaskUpdate      = errors.New("invalid Task update")
+
+	ErrTaskConvert = errors.New("Task conversion error")
+
+	// taskDataPlaceholder is the Data value set by NewTaskFromCondition.
+	taskDataPlaceholder = json.RawMessage(`{"empty": true}`)
 )
 
 const (
@@ -41,6 +47,9 @@ type Task[P, D any] struct {
 	// status holds informational data on the state
 	Status StatusRecord `json:"status"`
 
+	// Progress holds the step based progress of the work on this task.
+	Progress *Progress `json:"progress,omitempty"`
+
 	// Data holds Condition Task specific data
 	Data D `json:"data,omitempty"`
 
@@ -120,6 +129,11 @@ func (t *Task[P, D]) Update(update *Task[P, D]) error {
 	// update data
 	t.Data = update.Data
 
+	// update progress
+	if update.Progress != nil {
+		t.Progress = update.Progress
+	}
+
 	// update status
 	currTaskStatus, err := t.Status.Marshal()
 	if err != nil {
@@ -174,10 +188,106 @@ func NewTaskFromCondition(cond *Condition) *Task[any, any] {
 		State:         Pending,
 		Server:        &Server{UUID: cond.Target},
 		Parameters:    cond.Parameters,
-		Data:          json.RawMessage(`{"empty": true}`), // placeholder value
+		Data:          taskDataPlaceholder, // placeholder value
 		Fault:         cond.Fault,
 		Status:        *sr,
 		TraceID:       cond.TraceID,
 		SpanID:        cond.SpanID,
 	}
 }
+
+// ConvertTask returns the Task with its Parameters and Data decoded into the types P and D.
+//
+// The Parameters and Data are decoded strictly, fields unknown to the types are rejected.
+// The placeholder Data set by NewTaskFromCondition is converted into the zero value of D.
+func ConvertTask[P, D any](t *Task[any, any]) (*Task[P, D], error) {
+	params, err := json.Marshal(t.Parameters)
+	if err != nil {
+		return nil, errors.Wrap(ErrTaskConvert, "parameters: "+err.Error())
+	}
+
+	data, err := json.Marshal(t.Data)
+	if err != nil {
+		return nil, errors.Wrap(ErrTaskConvert, "data: "+err.Error())
+	}
+
+	return convertTask[P, D](t, params, data)
+}
+
+// TaskFromMessageAs converts a Task json.RawMessage object into a Task with its Parameters and Data
+// decoded strictly into the types P and D, the Task is upgraded from older versions as with DecodeTask.
+func TaskFromMessageAs[P, D any](msg json.RawMessage) (*Task[P, D], error) {
+	t, err := DecodeTask[json.RawMessage, json.RawMessage](msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertTask[P, D](t, t.Parameters, t.Data)
+}
+
+// Generic returns the Task with its Parameters and Data encoded as JSON, for publishing
+// through the interfaces accepting a Task[any, any].
+func (t *Task[P, D]) Generic() (*Task[any, any], error) {
+	params, err := json.Marshal(t.Parameters)
+	if err != nil {
+		return nil, errors.Wrap(ErrTaskConvert, "parameters: "+err.Error())
+	}
+
+	data, err := json.Marshal(t.Data)
+	if err != nil {
+		return nil, errors.Wrap(ErrTaskConvert, "data: "+err.Error())
+	}
+
+	return withTaskFields[any, any](t, json.RawMessage(params), json.RawMessage(data)), nil
+}
+
+func convertTask[P, D, SP, SD any](t *Task[SP, SD], rawParams, rawData json.RawMessage) (*Task[P, D], error) {
+	var params P
+	if err := decodeStrict(rawParams, &params); err != nil {
+		return nil, errors.Wrap(ErrTaskConvert, fmt.Sprintf("parameters into %T: %s", params, err.Error()))
+	}
+
+	var data D
+	if equal, _ := statusRecordBytesEqual(rawData, taskDataPlaceholder); !equal {
+		if err := decodeStrict(rawData, &data); err != nil {
+			return nil, errors.Wrap(ErrTaskConvert, fmt.Sprintf("data into %T: %s", data, err.Error()))
+		}
+	}
+
+	return withTaskFields(t, params, data), nil
+}
+
+// withTaskFields returns a copy of the Task with the given Parameters and Data.
+func withTaskFields[P, D, SP, SD any](t *Task[SP, SD], params P, data D) *Task[P, D] {
+	return &Task[P, D]{
+		StructVersion: t.StructVersion,
+		ID:            t.ID,
+		Kind:          t.Kind,
+		State:         t.State,
+		Status:        t.Status,
+		Progress:      t.Progress,
+		Data:          data,
+		Parameters:    params,
+		Fault:         t.Fault,
+		FacilityCode:  t.FacilityCode,
+		Server:        t.Server,
+		WorkerID:      t.WorkerID,
+		TraceID:       t.TraceID,
+		SpanID:        t.SpanID,
+		CreatedAt:     t.CreatedAt,
+		UpdatedAt:     t.UpdatedAt,
+		CompletedAt:   t.CompletedAt,
+	}
+}
+
+// decodeStrict decodes the JSON into v rejecting unknown fields, empty and null values are skipped.
+func decodeStrict(raw json.RawMessage, v any) error {
+	if len(bytes.TrimSpace(raw)) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(v)
+}
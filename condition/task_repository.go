@@ -0,0 +1,224 @@
+package condition
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-automata/rivets/events"
+	"github.com/metal-automata/rivets/events/pkg/kv"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrTaskRepositoryKey = errors.New("Task repository key requires the facility code, kind and server")
+
+	taskKVDescription = "Condition Tasks being worked on by controllers"
+)
+
+// TaskFilter selects the Tasks returned by the TaskRepository List and Watch methods,
+// empty fields match any value.
+type TaskFilter struct {
+	FacilityCode string
+	Kind         Kind
+	ServerID     string
+}
+
+// pattern returns the KV key pattern matching the filter.
+//
+// Kinds may contain dots, when the Kind is not set the server cannot be matched by the
+// pattern and is instead matched by the key suffix.
+func (f *TaskFilter) pattern() string {
+	facility := "*"
+	if f != nil && f.FacilityCode != "" {
+		facility = f.FacilityCode
+	}
+
+	if f == nil || f.Kind == "" {
+		return facility + ".>"
+	}
+
+	server := "*"
+	if f.ServerID != "" {
+		server = f.ServerID
+	}
+
+	return TaskKVRepositoryKey(facility, f.Kind, server)
+}
+
+func (f *TaskFilter) match(key string) bool {
+	if f == nil {
+		return true
+	}
+
+	tokens := strings.Split(key, ".")
+	if len(tokens) < 3 {
+		return false
+	}
+
+	facility, server := tokens[0], tokens[len(tokens)-1]
+	kind := Kind(strings.Join(tokens[1:len(tokens)-1], "."))
+
+	return (f.FacilityCode == "" || f.FacilityCode == facility) &&
+		(f.Kind == "" || f.Kind == kind) &&
+		(f.ServerID == "" || f.ServerID == server)
+}
+
+// TaskRepository stores the Tasks being worked on in the TaskKVRepositoryBucket,
+// keyed by TaskKVRepositoryKey.
+type TaskRepository struct {
+	store *kv.Typed[Task[any, any]]
+}
+
+// NewTaskRepository returns a TaskRepository on the Task KV bucket, creating the bucket if required.
+func NewTaskRepository(njs *events.NatsJetstream, opts ...kv.Option) (*TaskRepository, error) {
+	opts = append([]kv.Option{kv.WithDescription(taskKVDescription)}, opts...)
+
+	handle, err := kv.CreateOrBindKVBucket(njs, TaskKVRepositoryBucket, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTaskRepositoryFromKV(handle), nil
+}
+
+// NewTaskRepositoryFromKV returns a TaskRepository on the given KV bucket.
+func NewTaskRepositoryFromKV(handle nats.KeyValue) *TaskRepository {
+	return &TaskRepository{store: kv.NewTyped[Task[any, any]](handle)}
+}
+
+// KeyValue exposes the underlying nats.KeyValue
+func (r *TaskRepository) KeyValue() nats.KeyValue {
+	return r.store.KeyValue()
+}
+
+func taskKey(t *Task[any, any]) (string, error) {
+	if t.FacilityCode == "" || t.Kind == "" || t.Server == nil || t.Server.UUID == uuid.Nil {
+		return "", errors.Wrap(ErrTaskRepositoryKey, "task: "+t.ID.String())
+	}
+
+	return TaskKVRepositoryKey(t.FacilityCode, t.Kind, t.Server.UUID.String()), nil
+}
+
+// Create stores a new Task, nats.ErrKeyExists is returned if a Task exists for the server and kind.
+func (r *TaskRepository) Create(task *Task[any, any]) error {
+	key, err := taskKey(task)
+	if err != nil {
+		return err
+	}
+
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+
+	_, err = r.store.Create(key, *task)
+
+	return err
+}
+
+// Get returns the Task for the server and kind, nats.ErrKeyNotFound is returned if there is none.
+func (r *TaskRepository) Get(facilityCode string, kind Kind, serverID string) (*Task[any, any], error) {
+	entry, err := r.store.Get(TaskKVRepositoryKey(facilityCode, kind, serverID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry.Value, nil
+}
+
+// Update applies the update to the stored Task as with Task.Update, with a compare-and-swap
+// on the KV revision retried when another writer updated the Task in between.
+//
+// nats.ErrKeyNotFound is returned when the Task does not exist, the updated Task is returned on success.
+func (r *TaskRepository) Update(update *Task[any, any]) (*Task[any, any], error) {
+	key, err := taskKey(update)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := r.store.Update(key, func(current *Task[any, any]) error {
+		// stored Tasks always have a Kind, its absence indicates the key does not exist
+		if current.Kind == "" {
+			return nats.ErrKeyNotFound
+		}
+
+		return current.Update(update)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry.Value, nil
+}
+
+// Delete removes the Task for the server and kind.
+func (r *TaskRepository) Delete(facilityCode string, kind Kind, serverID string) error {
+	return r.store.Delete(TaskKVRepositoryKey(facilityCode, kind, serverID))
+}
+
+// List returns the Tasks matching the filter, a nil filter returns all Tasks.
+func (r *TaskRepository) List(ctx context.Context, filter *TaskFilter) ([]*Task[any, any], error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, err := r.store.Watch(ctx, []string{filter.pattern()}, kv.WatchIgnoreDeletes())
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []*Task[any, any]{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case ev, ok := <-updates:
+			if !ok {
+				return nil, ctx.Err()
+			}
+
+			if ev.Op == kv.OpSynced {
+				return tasks, nil
+			}
+
+			if ev.Err != nil || !filter.match(ev.Key) {
+				continue
+			}
+
+			task := ev.Value
+			tasks = append(tasks, &task)
+		}
+	}
+}
+
+// Watch sends changes to the Tasks matching the filter on the returned channel until the context
+// is canceled, the current Tasks are sent first unless kv.WatchUpdatesOnly is given.
+func (r *TaskRepository) Watch(ctx context.Context, filter *TaskFilter,
+	opts ...kv.WatchOption) (<-chan kv.WatchEvent[Task[any, any]], error) {
+	updates, err := r.store.Watch(ctx, []string{filter.pattern()}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan kv.WatchEvent[Task[any, any]])
+
+	go func() {
+		defer close(ch)
+
+		for ev := range updates {
+			if ev.Op != kv.OpSynced && !filter.match(ev.Key) {
+				continue
+			}
+
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,137 @@
+package condition
+
+//nolint:all // test file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-automata/rivets/events"
+	"github.com/metal-automata/rivets/events/pkg/kv"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTask(facilityCode string, kind Kind, serverID uuid.UUID) *Task[any, any] {
+	task := NewTaskFromCondition(&Condition{ID: uuid.New(), Kind: kind, Target: serverID})
+	task.FacilityCode = facilityCode
+
+	return task
+}
+
+func TestTaskRepository(t *testing.T) {
+	srv := startJetStreamServer(t)
+	defer shutdownJetStream(t, srv)
+	nc, _ := jetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	repo, err := NewTaskRepository(evJS)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	serverA, serverB := uuid.New(), uuid.New()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watched, err := repo.Watch(watchCtx, &TaskFilter{ServerID: serverA.String()}, kv.WatchUpdatesOnly())
+	require.NoError(t, err)
+
+	// create
+	task := testTask("fac13", FirmwareInstall, serverA)
+	require.NoError(t, repo.Create(task))
+	require.ErrorIs(t, repo.Create(task), nats.ErrKeyExists)
+
+	err = repo.Create(&Task[any, any]{ID: uuid.New(), Kind: Inventory})
+	assert.True(t, errors.Is(err, ErrTaskRepositoryKey))
+
+	select {
+	case ev := <-watched:
+		assert.Equal(t, kv.OpPut, ev.Op)
+		assert.Equal(t, task.ID, ev.Value.ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the watch event")
+	}
+
+	require.NoError(t, repo.Create(testTask("fac13", BrokerAcquireServer, serverA)))
+	require.NoError(t, repo.Create(testTask("fac13", Inventory, serverB)))
+	require.NoError(t, repo.Create(testTask("fac42", FirmwareInstall, serverB)))
+
+	// get
+	got, err := repo.Get("fac13", FirmwareInstall, serverA.String())
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, got.ID)
+	assert.Equal(t, Pending, got.State)
+
+	_, err = repo.Get("fac13", Inventory, serverA.String())
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+
+	// update
+	update := testTask("fac13", FirmwareInstall, serverA)
+	update.ID = task.ID
+	update.State = Active
+	update.WorkerID = "flasher-abc"
+	update.Status = NewTaskStatusRecord("installing bmc firmware")
+
+	updated, err := repo.Update(update)
+	require.NoError(t, err)
+	assert.Equal(t, Active, updated.State)
+	assert.Equal(t, "flasher-abc", updated.WorkerID)
+	assert.Equal(t, "installing bmc firmware", updated.Status.Last())
+
+	got, err = repo.Get("fac13", FirmwareInstall, serverA.String())
+	require.NoError(t, err)
+	assert.Equal(t, Active, got.State)
+
+	// Task.Update rules apply
+	mismatch := testTask("fac13", FirmwareInstall, serverA)
+	_, err = repo.Update(mismatch)
+	assert.True(t, errors.Is(err, errTaskUpdate))
+
+	_, err = repo.Update(testTask("fac13", VirtualMediaMount, serverA))
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+
+	// list
+	for _, tc := range []struct {
+		name   string
+		filter *TaskFilter
+		count  int
+	}{
+		{"all", nil, 4},
+		{"facility", &TaskFilter{FacilityCode: "fac13"}, 3},
+		{"kind", &TaskFilter{Kind: FirmwareInstall}, 2},
+		{"dotted kind", &TaskFilter{FacilityCode: "fac13", Kind: BrokerAcquireServer}, 1},
+		{"server", &TaskFilter{ServerID: serverA.String()}, 2},
+		{"facility and server", &TaskFilter{FacilityCode: "fac42", ServerID: serverB.String()}, 1},
+		{"none", &TaskFilter{FacilityCode: "fac99"}, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tasks, err := repo.List(ctx, tc.filter)
+			require.NoError(t, err)
+			assert.Len(t, tasks, tc.count)
+		})
+	}
+
+	// delete
+	require.NoError(t, repo.Delete("fac13", FirmwareInstall, serverA.String()))
+	_, err = repo.Get("fac13", FirmwareInstall, serverA.String())
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+
+	// the watch skipped the other server and sent the updates to server A
+	ops := []kv.Operation{}
+	timeout := time.After(5 * time.Second)
+	for len(ops) < 3 {
+		select {
+		case ev := <-watched:
+			assert.Contains(t, ev.Key, serverA.String())
+			ops = append(ops, ev.Op)
+		case <-timeout:
+			t.Fatalf("timed out waiting for watch events, got=%v", ops)
+		}
+	}
+	assert.Equal(t, []kv.Operation{kv.OpPut, kv.OpPut, kv.OpDelete}, ops)
+}
@@ -110,14 +110,14 @@ func TestTaskUpdate(t *testing.T) {
 				Kind:     FirmwareInstall,
 				State:    Active,
 				WorkerID: "worker-123",
-				Status:   StatusRecord{[]StatusMsg{{Msg: "update"}}},
+				Status:   StatusRecord{StatusMsgs: []StatusMsg{{Msg: "update"}}},
 			},
 			expectedTask: Task[any, any]{
 				ID:       taskID,
 				Kind:     FirmwareInstall,
 				State:    Active,
 				WorkerID: "worker-123",
-				Status:   StatusRecord{[]StatusMsg{{Msg: "update"}}},
+				Status:   StatusRecord{StatusMsgs: []StatusMsg{{Msg: "update"}}},
 			},
 			expectedErr: nil,
 		},
@@ -219,7 +219,7 @@ func TestTaskFromMessage(t *testing.T) {
 				ID:            uuid.MustParse("910e03b0-f84d-4c88-9eeb-e07f35710d03"),
 				Kind:          FirmwareInstall,
 				State:         Pending,
-				Status:        StatusRecord{[]StatusMsg{{Msg: "initialized"}}},
+				Status:        StatusRecord{StatusMsgs: []StatusMsg{{Msg: "initialized"}}},
 				Data: map[string]interface{}{
 					"empty": true,
 				},
@@ -263,3 +263,71 @@ func TestTaskFromMessage(t *testing.T) {
 		})
 	}
 }
+
+type testFirmwareInstallData struct {
+	Installed []string `json:"installed"`
+	Attempts  int      `json:"attempts"`
+}
+
+func TestConvertTask(t *testing.T) {
+	assetID := uuid.New()
+	params := &FirmwareInstallTaskParameters{AssetID: assetID, FirmwareSetID: uuid.New(), ForceInstall: true}
+
+	cond := &Condition{
+		ID:         uuid.New(),
+		Kind:       FirmwareInstall,
+		Target:     assetID,
+		Parameters: params.MustJSON(),
+	}
+
+	// the placeholder Data converts into the zero value
+	generic := NewTaskFromCondition(cond)
+	task, err := ConvertTask[*FirmwareInstallTaskParameters, *testFirmwareInstallData](generic)
+	assert.NoError(t, err)
+	assert.Equal(t, params, task.Parameters)
+	assert.Nil(t, task.Data)
+	assert.Equal(t, cond.ID, task.ID)
+	assert.Equal(t, Pending, task.State)
+
+	// the reverse conversion for publishing
+	task.Data = &testFirmwareInstallData{Installed: []string{"bmc"}, Attempts: 1}
+	task.State = Active
+
+	generic, err = task.Generic()
+	assert.NoError(t, err)
+	assert.Equal(t, Active, generic.State)
+
+	msg, err := generic.Marshal()
+	assert.NoError(t, err)
+
+	// a task decoded from the message, as with TaskFromMessage
+	fromMsg, err := TaskFromMessage(msg)
+	assert.NoError(t, err)
+	assert.IsType(t, map[string]interface{}{}, fromMsg.Parameters)
+
+	converted, err := ConvertTask[*FirmwareInstallTaskParameters, *testFirmwareInstallData](fromMsg)
+	assert.NoError(t, err)
+	assertTaskEqual(t, task, converted)
+
+	typed, err := TaskFromMessageAs[*FirmwareInstallTaskParameters, *testFirmwareInstallData](msg)
+	assert.NoError(t, err)
+	assertTaskEqual(t, task, typed)
+
+	// unknown fields are rejected
+	fromMsg.Data = map[string]interface{}{"installed": []string{"bmc"}, "retries": 2}
+	_, err = ConvertTask[*FirmwareInstallTaskParameters, *testFirmwareInstallData](fromMsg)
+	assert.True(t, errors.Is(err, ErrTaskConvert))
+
+	_, err = TaskFromMessageAs[*InventoryTaskParameters, *testFirmwareInstallData](msg)
+	assert.True(t, errors.Is(err, ErrTaskConvert))
+}
+
+func assertTaskEqual[P, D any](t *testing.T, expected, got *Task[P, D]) {
+	t.Helper()
+
+	// status timestamps lose their location when encoded
+	assert.Equal(t, expected.Status.Last(), got.Status.Last())
+
+	got.Status = expected.Status
+	assert.Equal(t, expected, got)
+}
@@ -1,12 +1,14 @@
 package condition
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/metal-automata/rivets/events"
+	"github.com/metal-automata/rivets/events/registry"
 	"golang.org/x/exp/slices"
 
 	fleetdbapi "github.com/metal-automata/fleetdb/pkg/api/v1"
@@ -44,6 +46,7 @@ const (
 	Active    State = "active"
 	Failed    State = "failed"
 	Succeeded State = "succeeded"
+	Canceled  State = "canceled"
 )
 
 // States returns available condition states.
@@ -53,17 +56,18 @@ func States() []State {
 		Pending,
 		Failed,
 		Succeeded,
+		Canceled,
 	}
 }
 
 // Transition valid returns a bool value if the state transition is allowed.
 func (current State) TransitionValid(next State) bool {
 	switch {
-	// Pending state can stay in Pending or transition to Active or Failed or Succeeded
-	case current == Pending && slices.Contains([]State{Pending, Active, Failed, Succeeded}, next):
+	// Pending state can stay in Pending or transition to Active or Failed or Succeeded or Canceled
+	case current == Pending && slices.Contains([]State{Pending, Active, Failed, Succeeded, Canceled}, next):
 		return true
-	// Active state can stay in Active or transition to Failed or Succeeded
-	case current == Active && slices.Contains([]State{Active, Failed, Succeeded}, next):
+	// Active state can stay in Active or transition to Failed or Succeeded or Canceled
+	case current == Active && slices.Contains([]State{Active, Failed, Succeeded, Canceled}, next):
 		return true
 	default:
 		return false
@@ -77,7 +81,7 @@ func StateIsValid(s State) bool {
 
 // StateComplete returns true when the given state is considered to be final.
 func StateIsComplete(s State) bool {
-	return slices.Contains([]State{Failed, Succeeded}, s)
+	return slices.Contains([]State{Failed, Succeeded, Canceled}, s)
 }
 
 // Parameters is an interface for Condition Parameter types
@@ -171,6 +175,24 @@ func (c *Condition) StreamPublishSubject(facilityCode string) string {
 	return fmt.Sprintf("%s.servers.%s", facilityCode, c.Kind)
 }
 
+// CapableControllers returns the live controllers that can run the Condition in the facility,
+// orchestrators call this before publishing to fail fast when no controller can pick it up.
+//
+// A *registry.ErrNoCapableController is returned when there are none.
+func (c *Condition) CapableControllers(ctx context.Context, reg *registry.Registry, facilityCode string) ([]*registry.Controller, error) {
+	return reg.CapableControllers(ctx, string(c.Kind), facilityCode)
+}
+
+// AdvertiseKinds returns the registry option for a controller to advertise the Condition kinds it handles.
+func AdvertiseKinds(kinds ...Kind) registry.RegisterOption {
+	s := make([]string, 0, len(kinds))
+	for _, k := range kinds {
+		s = append(s, string(k))
+	}
+
+	return registry.WithConditionKinds(s...)
+}
+
 // Fault is used to introduce faults into the controller when executing on a condition.
 //
 // Note: this depends on controllers implementing support to honor the given fault.
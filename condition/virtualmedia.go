@@ -1,6 +1,10 @@
 package condition
 
-import "github.com/google/uuid"
+import (
+	"net/url"
+
+	"github.com/google/uuid"
+)
 
 type (
 	VirtualMediaType        string
@@ -28,22 +32,22 @@ type VirtualMediaTaskParameters struct {
 	// MountMethod specifies the means of obtaining the image to be mounted
 	//
 	// Required: true
-	MountMethod VirtualMediaMountMethod `json:"mount_method"`
+	MountMethod VirtualMediaMountMethod `json:"mount_method" jsonschema:"required"`
 
 	// ImageURL is a URL accessible to the Disko controller and the BMC to download the image.
 	//
 	// Required: true
-	ImageURL string `json:"image_uri"`
+	ImageURL string `json:"image_uri" jsonschema:"required"`
 
 	// MediaType indicates the kind of media being uploaded/mounted
 	//
 	// Required: true
-	MediaType VirtualMediaType `json:"media_type"`
+	MediaType VirtualMediaType `json:"media_type" jsonschema:"required"`
 
 	// Identifier for the Asset in the Asset store.
 	//
 	// Required: true
-	AssetID uuid.UUID `json:"asset_id"`
+	AssetID uuid.UUID `json:"asset_id" jsonschema:"required"`
 }
 
 func NewVirtualMediaTaskParameters(assetID uuid.UUID, imageURL string, mediaType VirtualMediaType, mountMethod VirtualMediaMountMethod) *VirtualMediaTaskParameters {
@@ -54,3 +58,19 @@ func NewVirtualMediaTaskParameters(assetID uuid.UUID, imageURL string, mediaType
 		MountMethod: mountMethod,
 	}
 }
+
+// Validate implements the Parameters interface.
+func (p *VirtualMediaTaskParameters) Validate() error {
+	v := &fieldValidator{}
+	v.requireID("asset_id", p.AssetID)
+	v.oneOf("mount_method", string(p.MountMethod), string(MountMethodUpload), string(MountMethodURL))
+	v.oneOf("media_type", string(p.MediaType), string(MediaTypeFloppy), string(MediaTypeISO))
+
+	if p.ImageURL == "" {
+		v.add("image_uri", nil, "required")
+	} else if u, err := url.Parse(p.ImageURL); err != nil || !u.IsAbs() {
+		v.add("image_uri", p.ImageURL, "expected an absolute URL")
+	}
+
+	return v.err()
+}
@@ -0,0 +1,395 @@
+package condition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-automata/rivets/events/pkg/kv"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// WorkflowKVBucket is the KV bucket holding Workflow progress.
+	WorkflowKVBucket = "condition-workflows"
+
+	WorkflowVersion1 = "1.0"
+)
+
+var (
+	ErrWorkflowInvalid = errors.New("invalid Workflow")
+	ErrWorkflowPersist = errors.New("error persisting Workflow progress")
+)
+
+// WorkflowKVKey returns the KV key for the Workflow progress record.
+func WorkflowKVKey(facilityCode, workflowID string) string {
+	return fmt.Sprintf("%s.%s", facilityCode, workflowID)
+}
+
+// StepCondition limits a step to run only when an earlier step ended in one of the given States.
+type StepCondition struct {
+	// Step is the name of the earlier step.
+	Step string `json:"step"`
+	// States are the final States of the earlier step for which this step runs.
+	States []State `json:"states"`
+}
+
+// WorkflowStep is a single Condition in a Workflow.
+//
+// nolint:govet // fieldalignment struct is easier to read in the current format
+type WorkflowStep struct {
+	// Name identifies the step within the Workflow, this defaults to the step Kind.
+	Name string `json:"name"`
+
+	// Kind is the Condition Kind run by this step.
+	Kind Kind `json:"kind"`
+
+	// Parameters are the Condition Parameters for this step.
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+
+	// RunIf when set, runs this step only if the condition is met, the step is skipped otherwise.
+	RunIf *StepCondition `json:"runIf,omitempty"`
+
+	// ConditionID is the identifier of the Condition created for this step.
+	ConditionID uuid.UUID `json:"conditionID,omitempty"`
+
+	// State is the State of the step Condition.
+	State State `json:"state"`
+
+	// Skipped is set when the step was not run.
+	Skipped bool `json:"skipped,omitempty"`
+
+	// Error holds the error returned when the step could not be run.
+	Error string `json:"error,omitempty"`
+
+	StartedAt   time.Time `json:"startedAt,omitempty"`
+	CompletedAt time.Time `json:"completedAt,omitempty"`
+}
+
+// complete returns true once the step has run or was skipped.
+func (s *WorkflowStep) complete() bool {
+	return s.Skipped || StateIsComplete(s.State)
+}
+
+// Workflow is an ordered sequence of Conditions run on a target server.
+//
+// The steps are run in order, once a step fails or is canceled the remaining steps are
+// skipped and the OnFailure steps are run, for example to release an acquired server.
+//
+// nolint:govet // fieldalignment struct is easier to read in the current format
+type Workflow struct {
+	// Version identifies the revision number for this struct.
+	Version string `json:"version"`
+
+	// ID is the identifier for this Workflow.
+	ID uuid.UUID `json:"id"`
+
+	// Name is a descriptive name for the Workflow.
+	Name string `json:"name"`
+
+	// Client is the user/jwt user that requested the Workflow.
+	Client string `json:"client,omitempty"`
+
+	// Target is the identifier for the target server of the Workflow steps.
+	Target uuid.UUID `json:"target"`
+
+	TraceID string `json:"traceID,omitempty"`
+	SpanID  string `json:"spanID,omitempty"`
+
+	// Steps are the Conditions run in order.
+	Steps []*WorkflowStep `json:"steps"`
+
+	// OnFailure are the Conditions run in order when a step fails or is canceled.
+	OnFailure []*WorkflowStep `json:"onFailure,omitempty"`
+
+	// State is the overall Workflow State derived from the states of its steps.
+	State State `json:"state"`
+
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+// NewWorkflow returns a Workflow running the steps on the target server,
+// the onFailure steps are run when a step fails or is canceled.
+//
+// Step names default to the step Kind, steps of the same Kind require distinct names.
+func NewWorkflow(name string, target uuid.UUID, steps, onFailure []*WorkflowStep) *Workflow {
+	for _, s := range append(slices.Clone(steps), onFailure...) {
+		if s.Name == "" {
+			s.Name = string(s.Kind)
+		}
+
+		if s.State == "" {
+			s.State = Pending
+		}
+	}
+
+	return &Workflow{
+		Version:   WorkflowVersion1,
+		ID:        uuid.New(),
+		Name:      name,
+		Target:    target,
+		Steps:     steps,
+		OnFailure: onFailure,
+		State:     Pending,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Validate checks the Workflow steps are runnable.
+func (w *Workflow) Validate() error {
+	if w.Target == uuid.Nil {
+		return errors.Wrap(ErrWorkflowInvalid, "target not set")
+	}
+
+	if len(w.Steps) == 0 {
+		return errors.Wrap(ErrWorkflowInvalid, "no steps defined")
+	}
+
+	seen := map[string]bool{}
+	for _, s := range append(slices.Clone(w.Steps), w.OnFailure...) {
+		if s.Kind == "" {
+			return errors.Wrap(ErrWorkflowInvalid, "step kind not set: "+s.Name)
+		}
+
+		if s.Name == "" || seen[s.Name] {
+			return errors.Wrap(ErrWorkflowInvalid, "step name empty or duplicate: "+s.Name)
+		}
+
+		// a step may only depend on an earlier step
+		if s.RunIf != nil && !seen[s.RunIf.Step] {
+			return errors.Wrap(ErrWorkflowInvalid, "step depends on unknown or later step: "+s.RunIf.Step)
+		}
+
+		seen[s.Name] = true
+	}
+
+	return nil
+}
+
+// Step returns the step with the given name, or nil when there is none.
+func (w *Workflow) Step(name string) *WorkflowStep {
+	for _, s := range append(slices.Clone(w.Steps), w.OnFailure...) {
+		if s.Name == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// failedState returns Failed if any of the steps failed, Canceled if any was canceled,
+// and an empty State otherwise.
+func (w *Workflow) failedState() State {
+	var state State
+
+	for _, s := range w.Steps {
+		switch s.State {
+		case Failed:
+			return Failed
+		case Canceled:
+			state = Canceled
+		}
+	}
+
+	return state
+}
+
+// DeriveState returns the overall Workflow State from the states of its steps.
+//
+// A Workflow with a failed or canceled step remains Active until its OnFailure steps complete.
+func (w *Workflow) DeriveState() State {
+	if failed := w.failedState(); failed != "" {
+		for _, s := range w.OnFailure {
+			if !s.complete() {
+				return Active
+			}
+		}
+
+		return failed
+	}
+
+	started := false
+	complete := true
+
+	for _, s := range w.Steps {
+		if (s.State != "" && s.State != Pending) || s.Skipped {
+			started = true
+		}
+
+		if !s.complete() {
+			complete = false
+		}
+	}
+
+	switch {
+	case complete:
+		return Succeeded
+	case started:
+		return Active
+	default:
+		return Pending
+	}
+}
+
+// IsComplete returns true when the Workflow State is final.
+func (w *Workflow) IsComplete() bool {
+	return StateIsComplete(w.DeriveState())
+}
+
+func (w *Workflow) runIfMet(s *WorkflowStep) bool {
+	if s.RunIf == nil {
+		return true
+	}
+
+	dep := w.Step(s.RunIf.Step)
+
+	return dep != nil && slices.Contains(s.RunIf.States, dep.State)
+}
+
+// stepCondition returns the Condition for a step.
+func (w *Workflow) stepCondition(s *WorkflowStep) *Condition {
+	return &Condition{
+		Version:    ConditionStructVersion,
+		Client:     w.Client,
+		TraceID:    w.TraceID,
+		SpanID:     w.SpanID,
+		ID:         s.ConditionID,
+		Target:     w.Target,
+		Kind:       s.Kind,
+		Parameters: s.Parameters,
+		State:      Pending,
+		CreatedAt:  s.StartedAt,
+	}
+}
+
+// StepExecutor runs the Condition for a Workflow step and returns its final State.
+//
+// When a Workflow is resumed, a step that was started is run again with the same Condition ID,
+// the executor is expected to wait on the existing Condition in that case.
+type StepExecutor func(ctx context.Context, cond *Condition) (State, error)
+
+// WorkflowRunner runs Workflows and persists their progress in a KV bucket.
+type WorkflowRunner struct {
+	store        *kv.Typed[Workflow]
+	facilityCode string
+	execute      StepExecutor
+}
+
+// NewWorkflowRunner returns a WorkflowRunner persisting Workflow progress in the KV bucket handle.
+func NewWorkflowRunner(handle nats.KeyValue, facilityCode string, execute StepExecutor) *WorkflowRunner {
+	return &WorkflowRunner{
+		store:        kv.NewTyped[Workflow](handle),
+		facilityCode: facilityCode,
+		execute:      execute,
+	}
+}
+
+// GetWorkflow returns the Workflow progress record from the KV bucket.
+func GetWorkflow(handle nats.KeyValue, facilityCode, workflowID string) (*Workflow, error) {
+	entry, err := kv.NewTyped[Workflow](handle).Get(WorkflowKVKey(facilityCode, workflowID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry.Value, nil
+}
+
+// Resume continues running a Workflow from its persisted progress.
+func (r *WorkflowRunner) Resume(ctx context.Context, workflowID string) (*Workflow, error) {
+	entry, err := r.store.Get(WorkflowKVKey(r.facilityCode, workflowID))
+	if err != nil {
+		return nil, err
+	}
+
+	wf := &entry.Value
+
+	return wf, r.Run(ctx, wf)
+}
+
+// Run runs the Workflow steps in order, skipping the steps already complete.
+//
+// A failed step does not return an error, the Workflow State is Failed once its OnFailure steps complete.
+// When the context is canceled the progress up to that point is persisted and the context error is
+// returned, the Workflow can then be continued with Resume.
+func (r *WorkflowRunner) Run(ctx context.Context, wf *Workflow) error {
+	if err := wf.Validate(); err != nil {
+		return err
+	}
+
+	if err := r.persist(wf); err != nil {
+		return err
+	}
+
+	for _, step := range wf.Steps {
+		if err := r.runStep(ctx, wf, step, wf.failedState() == ""); err != nil {
+			return err
+		}
+	}
+
+	if wf.failedState() != "" {
+		for _, step := range wf.OnFailure {
+			if err := r.runStep(ctx, wf, step, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return r.persist(wf)
+}
+
+func (r *WorkflowRunner) runStep(ctx context.Context, wf *Workflow, step *WorkflowStep, run bool) error {
+	if step.complete() {
+		return nil
+	}
+
+	if !run || !wf.runIfMet(step) {
+		step.Skipped = true
+		return r.persist(wf)
+	}
+
+	if step.ConditionID == uuid.Nil {
+		step.ConditionID = uuid.New()
+		step.StartedAt = time.Now()
+	}
+
+	step.State = Active
+	if err := r.persist(wf); err != nil {
+		return err
+	}
+
+	state, err := r.execute(ctx, wf.stepCondition(step))
+	if ctx.Err() != nil {
+		// the step remains active to be picked up on resume
+		return ctx.Err()
+	}
+
+	switch {
+	case err != nil:
+		state = Failed
+		step.Error = err.Error()
+	case !StateIsComplete(state):
+		step.Error = "step returned non final state: " + string(state)
+		state = Failed
+	}
+
+	step.State = state
+	step.CompletedAt = time.Now()
+
+	return r.persist(wf)
+}
+
+func (r *WorkflowRunner) persist(wf *Workflow) error {
+	wf.State = wf.DeriveState()
+	wf.UpdatedAt = time.Now()
+
+	if _, err := r.store.Put(WorkflowKVKey(r.facilityCode, wf.ID.String()), *wf); err != nil {
+		return errors.Wrap(ErrWorkflowPersist, err.Error())
+	}
+
+	return nil
+}
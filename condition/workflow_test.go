@@ -0,0 +1,191 @@
+package condition
+
+//nolint:all // test file
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExecutor struct {
+	mu      sync.Mutex
+	results map[Kind]State
+	errs    map[Kind]error
+	ran     []Kind
+	conds   map[Kind]uuid.UUID
+}
+
+func (f *fakeExecutor) execute(_ context.Context, cond *Condition) (State, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.ran = append(f.ran, cond.Kind)
+	if f.conds == nil {
+		f.conds = map[Kind]uuid.UUID{}
+	}
+	f.conds[cond.Kind] = cond.ID
+
+	if err := f.errs[cond.Kind]; err != nil {
+		return "", err
+	}
+
+	if s, ok := f.results[cond.Kind]; ok {
+		return s, nil
+	}
+
+	return Succeeded, nil
+}
+
+func testWorkflow(target uuid.UUID) *Workflow {
+	return NewWorkflow("firmware install", target,
+		[]*WorkflowStep{
+			{Kind: BrokerAcquireServer},
+			{Kind: FirmwareInstall},
+			{Kind: Inventory},
+			{Name: "release", Kind: BrokerReleaseServer},
+		},
+		[]*WorkflowStep{
+			{Name: "release-on-failure", Kind: BrokerReleaseServer},
+		},
+	)
+}
+
+func TestWorkflowDeriveState(t *testing.T) {
+	wf := testWorkflow(uuid.New())
+	require.NoError(t, wf.Validate())
+	assert.Equal(t, Pending, wf.DeriveState())
+
+	wf.Steps[0].State = Succeeded
+	wf.Steps[1].State = Active
+	assert.Equal(t, Active, wf.DeriveState())
+
+	wf.Steps[1].State = Failed
+	assert.Equal(t, Active, wf.DeriveState(), "failure handlers pending")
+
+	wf.OnFailure[0].State = Succeeded
+	assert.Equal(t, Failed, wf.DeriveState())
+
+	wf.Steps[1].State = Canceled
+	assert.Equal(t, Canceled, wf.DeriveState())
+
+	wf = testWorkflow(uuid.New())
+	for _, s := range wf.Steps {
+		s.State = Succeeded
+	}
+	assert.Equal(t, Succeeded, wf.DeriveState())
+	assert.True(t, wf.IsComplete())
+
+	// invalid workflows
+	assert.ErrorIs(t, NewWorkflow("empty", uuid.New(), nil, nil).Validate(), ErrWorkflowInvalid)
+	dup := NewWorkflow("dup", uuid.New(), []*WorkflowStep{{Kind: Inventory}, {Kind: Inventory}}, nil)
+	assert.ErrorIs(t, dup.Validate(), ErrWorkflowInvalid)
+	later := NewWorkflow("later", uuid.New(), []*WorkflowStep{
+		{Kind: Inventory, RunIf: &StepCondition{Step: string(FirmwareInstall), States: []State{Succeeded}}},
+		{Kind: FirmwareInstall},
+	}, nil)
+	assert.ErrorIs(t, later.Validate(), ErrWorkflowInvalid)
+}
+
+func TestWorkflowRunner(t *testing.T) {
+	srv := startJetStreamServer(t)
+	defer shutdownJetStream(t, srv)
+	_, js := jetStreamContext(t, srv)
+
+	handle, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: WorkflowKVBucket})
+	require.NoError(t, err)
+
+	facilityCode := "fac13"
+	ctx := context.Background()
+
+	t.Run("steps succeed", func(t *testing.T) {
+		exec := &fakeExecutor{}
+		wf := testWorkflow(uuid.New())
+
+		require.NoError(t, NewWorkflowRunner(handle, facilityCode, exec.execute).Run(ctx, wf))
+		assert.Equal(t, Succeeded, wf.State)
+		assert.Equal(t, []Kind{BrokerAcquireServer, FirmwareInstall, Inventory, BrokerReleaseServer}, exec.ran)
+		assert.Equal(t, Pending, wf.OnFailure[0].State)
+
+		got, err := GetWorkflow(handle, facilityCode, wf.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, Succeeded, got.State)
+		assert.Equal(t, exec.conds[FirmwareInstall], got.Step(string(FirmwareInstall)).ConditionID)
+	})
+
+	t.Run("step failure runs failure handlers", func(t *testing.T) {
+		exec := &fakeExecutor{
+			results: map[Kind]State{FirmwareInstall: Failed},
+		}
+		wf := testWorkflow(uuid.New())
+
+		require.NoError(t, NewWorkflowRunner(handle, facilityCode, exec.execute).Run(ctx, wf))
+		assert.Equal(t, Failed, wf.State)
+		assert.Equal(t, []Kind{BrokerAcquireServer, FirmwareInstall, BrokerReleaseServer}, exec.ran)
+		assert.True(t, wf.Step(string(Inventory)).Skipped)
+		assert.True(t, wf.Step("release").Skipped)
+		assert.Equal(t, Succeeded, wf.Step("release-on-failure").State)
+	})
+
+	t.Run("executor error fails the step", func(t *testing.T) {
+		exec := &fakeExecutor{
+			errs: map[Kind]error{BrokerAcquireServer: errors.New("publish failed")},
+		}
+		wf := testWorkflow(uuid.New())
+
+		require.NoError(t, NewWorkflowRunner(handle, facilityCode, exec.execute).Run(ctx, wf))
+		assert.Equal(t, Failed, wf.State)
+		assert.Equal(t, "publish failed", wf.Steps[0].Error)
+	})
+
+	t.Run("conditional step", func(t *testing.T) {
+		exec := &fakeExecutor{}
+		wf := NewWorkflow("conditional", uuid.New(),
+			[]*WorkflowStep{
+				{Kind: Inventory},
+				{
+					Kind:  FirmwareInstall,
+					RunIf: &StepCondition{Step: string(Inventory), States: []State{Failed}},
+				},
+			}, nil)
+
+		require.NoError(t, NewWorkflowRunner(handle, facilityCode, exec.execute).Run(ctx, wf))
+		assert.Equal(t, Succeeded, wf.State)
+		assert.Equal(t, []Kind{Inventory}, exec.ran)
+		assert.True(t, wf.Steps[1].Skipped)
+	})
+
+	t.Run("resume after interruption", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		interrupted := func(_ context.Context, cond *Condition) (State, error) {
+			if cond.Kind == FirmwareInstall {
+				cancel()
+				return "", context.Canceled
+			}
+			return Succeeded, nil
+		}
+
+		wf := testWorkflow(uuid.New())
+		err := NewWorkflowRunner(handle, facilityCode, interrupted).Run(cctx, wf)
+		require.ErrorIs(t, err, context.Canceled)
+
+		got, err := GetWorkflow(handle, facilityCode, wf.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, Active, got.State)
+		assert.Equal(t, Active, got.Step(string(FirmwareInstall)).State)
+		startedID := got.Step(string(FirmwareInstall)).ConditionID
+
+		exec := &fakeExecutor{}
+		resumed, err := NewWorkflowRunner(handle, facilityCode, exec.execute).Resume(ctx, wf.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, Succeeded, resumed.State)
+		assert.Equal(t, []Kind{FirmwareInstall, Inventory, BrokerReleaseServer}, exec.ran)
+		assert.Equal(t, startedID, exec.conds[FirmwareInstall])
+	})
+}
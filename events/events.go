@@ -3,6 +3,7 @@ package events
 
 import (
 	"context"
+	"time"
 )
 
 type (
@@ -87,6 +88,23 @@ type Message interface {
 	ExtractOtelTraceContext(ctx context.Context) context.Context
 }
 
+// StoredMessage is a read-only Message retrieved directly from the stream store.
+//
+// Messages read from the store are not associated with a consumer, the
+// Ack, Nak, Term and InProgress methods return ErrReadOnlyMsg.
+type StoredMessage interface {
+	Message
+
+	// Headers returns the headers set on the message when published.
+	Headers() map[string][]string
+
+	// Timestamp returns the time at which the message was stored in the stream.
+	Timestamp() time.Time
+
+	// Sequence returns the stream sequence number of the message.
+	Sequence() uint64
+}
+
 // NewStream returns a Stream implementation.
 func NewStream(parameters StreamParameters) (Stream, error) {
 	return NewNatsBroker(parameters)
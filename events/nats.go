@@ -6,6 +6,7 @@ import (
 	"log"
 	"reflect"
 	"slices"
+	"strconv"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
@@ -37,6 +38,9 @@ var (
 	// ErrNatsMsgPull is returned when theres and error pulling a message from a NATS Jetstream.
 	ErrNatsMsgPull = errors.New("error fetching message from NATS Jetstream")
 
+	// ErrNatsMsgGet is returned when theres an error retrieving a stored message from a NATS Jetstream.
+	ErrNatsMsgGet = errors.New("error retrieving stored message from NATS Jetstream")
+
 	// ErrSubscription is returned when an error in the consumer subscription occurs.
 	ErrSubscription = errors.New("error subscribing to stream")
 
@@ -436,6 +440,51 @@ func (n *NatsJetstream) PullOneMsg(ctx context.Context, subject string) (Message
 	return nil, errors.Wrap(ErrNatsMsgPull, "no message")
 }
 
+// GetLastMsg returns the last message stored in the stream for the given subject.
+//
+// This is the read side of PublishOverwrite, the subject is prepended with the
+// configured PublisherSubjectPrefix just as it would be when publishing.
+func (n *NatsJetstream) GetLastMsg(ctx context.Context, subjectSuffix string) (StoredMessage, error) {
+	if err := n.storedMsgPrereqs(); err != nil {
+		return nil, err
+	}
+
+	fullSubject := n.parameters.PublisherSubjectPrefix + "." + subjectSuffix
+
+	msg, err := n.jsctx.GetLastMsg(n.parameters.Stream.Name, fullSubject, nats.Context(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, ErrNatsMsgGet.Error()+" subject="+fullSubject)
+	}
+
+	return &storedMsg{msg: msg}, nil
+}
+
+// GetMsg returns the message stored in the stream with the given sequence number.
+func (n *NatsJetstream) GetMsg(ctx context.Context, sequence uint64) (StoredMessage, error) {
+	if err := n.storedMsgPrereqs(); err != nil {
+		return nil, err
+	}
+
+	msg, err := n.jsctx.GetMsg(n.parameters.Stream.Name, sequence, nats.Context(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, ErrNatsMsgGet.Error()+" sequence="+strconv.FormatUint(sequence, 10))
+	}
+
+	return &storedMsg{msg: msg}, nil
+}
+
+func (n *NatsJetstream) storedMsgPrereqs() error {
+	if n.jsctx == nil {
+		return errors.Wrap(ErrNatsMsgGet, "Jetstream context is not setup")
+	}
+
+	if n.parameters == nil || n.parameters.Stream == nil {
+		return errors.Wrap(ErrNatsMsgGet, "stream parameters not defined")
+	}
+
+	return nil
+}
+
 func (n *NatsJetstream) subscriptionCallback(msg *nats.Msg) {
 	select {
 	case <-time.After(subscriptionCallbackTimeout):
@@ -3,6 +3,7 @@ package events
 
 import (
 	"context"
+	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
@@ -10,6 +11,11 @@ import (
 	"go.opentelemetry.io/otel/propagation"
 )
 
+var (
+	// ErrReadOnlyMsg is returned when an acknowledgement is attempted on a message read from the stream store.
+	ErrReadOnlyMsg = errors.New("message retrieved from the stream store is read-only")
+)
+
 // here we implement the Message interface for nats.Msg
 
 // AsNatsMsg exposes the underlying nats.Msg to a sophisticated consumer.
@@ -61,3 +67,53 @@ func (nm *natsMsg) ExtractOtelTraceContext(ctx context.Context) context.Context
 
 	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(nm.msg.Header))
 }
+
+// here we implement the StoredMessage interface for nats.RawStreamMsg
+
+type storedMsg struct {
+	msg *nats.RawStreamMsg
+}
+
+func (sm *storedMsg) Ack() error {
+	return ErrReadOnlyMsg
+}
+
+func (sm *storedMsg) Nak() error {
+	return ErrReadOnlyMsg
+}
+
+func (sm *storedMsg) Term() error {
+	return ErrReadOnlyMsg
+}
+
+func (sm *storedMsg) InProgress() error {
+	return ErrReadOnlyMsg
+}
+
+func (sm *storedMsg) Subject() string {
+	return sm.msg.Subject
+}
+
+func (sm *storedMsg) Data() []byte {
+	return sm.msg.Data
+}
+
+func (sm *storedMsg) Headers() map[string][]string {
+	return sm.msg.Header
+}
+
+func (sm *storedMsg) Timestamp() time.Time {
+	return sm.msg.Time
+}
+
+func (sm *storedMsg) Sequence() uint64 {
+	return sm.msg.Sequence
+}
+
+func (sm *storedMsg) ExtractOtelTraceContext(ctx context.Context) context.Context {
+	if sm == nil || sm.msg.Header == nil {
+		return ctx
+	}
+
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(sm.msg.Header))
+}
@@ -143,6 +143,57 @@ func TestPublishAndSubscribe_WithRollup(t *testing.T) {
 	require.ErrorIs(t, err, context.DeadlineExceeded)
 }
 
+func TestGetLastMsgAndGetMsg(t *testing.T) {
+	jsSrv := natsTest.StartJetStreamServer(t)
+	defer natsTest.ShutdownJetStream(t, jsSrv)
+
+	jsConn, _ := natsTest.JetStreamContext(t, jsSrv)
+	njs := NewJetstreamFromConn(jsConn)
+	defer njs.Close()
+
+	njs.parameters = &NatsOptions{
+		AppName: "TestGetLastMsg",
+		Stream: &NatsStreamOptions{
+			Name:      "test_stream",
+			Subjects:  []string{"pre.>"},
+			Retention: "limits",
+		},
+		PublisherSubjectPrefix: "pre",
+	}
+	require.NoError(t, njs.addStream())
+
+	// nothing published yet
+	_, err := njs.GetLastMsg(context.TODO(), "fc13.servers.state")
+	require.Error(t, err)
+	require.ErrorIs(t, err, nats.ErrMsgNotFound)
+
+	require.NoError(t, njs.PublishOverwrite(context.TODO(), "fc13.servers.state", []byte("first")))
+	require.NoError(t, njs.PublishOverwrite(context.TODO(), "fc13.servers.state", []byte("second")))
+	require.NoError(t, njs.Publish(context.TODO(), "fc13.servers.other", []byte("other")))
+
+	msg, err := njs.GetLastMsg(context.TODO(), "fc13.servers.state")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), msg.Data())
+	assert.Equal(t, "pre.fc13.servers.state", msg.Subject())
+	assert.False(t, msg.Timestamp().IsZero())
+	assert.Equal(t, "sub", msg.Headers()["Nats-Rollup"][0])
+
+	// read-only messages cannot be acknowledged
+	assert.ErrorIs(t, msg.Ack(), ErrReadOnlyMsg)
+	assert.ErrorIs(t, msg.Nak(), ErrReadOnlyMsg)
+	assert.ErrorIs(t, msg.Term(), ErrReadOnlyMsg)
+	assert.ErrorIs(t, msg.InProgress(), ErrReadOnlyMsg)
+
+	byseq, err := njs.GetMsg(context.TODO(), msg.Sequence())
+	require.NoError(t, err)
+	assert.Equal(t, msg.Data(), byseq.Data())
+	assert.Equal(t, msg.Sequence(), byseq.Sequence())
+
+	// the first message was rolled up
+	_, err = njs.GetMsg(context.TODO(), 1)
+	require.ErrorIs(t, err, nats.ErrMsgNotFound)
+}
+
 func Test_addConsumer(t *testing.T) {
 	jsSrv := natsTest.StartJetStreamServer(t)
 	defer natsTest.ShutdownJetStream(t, jsSrv)
@@ -0,0 +1,180 @@
+// Package election provides leader election among controller replicas, for
+// jobs that must run on exactly one replica at a time.
+//
+// Leadership is a lease on the election name in a NATS KV bucket with a TTL,
+// a leader that stops renewing its lease is reaped once the TTL expires.
+//
+//nolint:wsl // useless
+package election
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/metal-automata/rivets/events"
+	"github.com/metal-automata/rivets/events/pkg/kv"
+	"github.com/metal-automata/rivets/events/pkg/lease"
+	"github.com/metal-automata/rivets/events/registry"
+)
+
+var (
+	BucketName    = "leader-election"
+	defaultTTL    = 30 * time.Second
+	kvDescription = "leaders elected among controller replicas"
+
+	ErrNotLeader = errors.New("not the leader")
+)
+
+// Election is a candidate in the election for a name.
+type Election struct {
+	name      string
+	candidate registry.ControllerID
+	ttl       time.Duration
+	bucket    []kv.Option
+	leases    *lease.Manager
+	mu        sync.Mutex
+	lease     *lease.Lease
+	callbacks []func(leader bool)
+}
+
+type Option func(e *Election)
+
+// WithTTL sets the period after which the leadership of an unresponsive leader expires.
+//
+// The TTL is set on the election KV bucket when it is created, all elections
+// sharing the bucket should use the same TTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(e *Election) {
+		e.ttl = ttl
+	}
+}
+
+// WithBucketOptions sets additional options on the election KV bucket.
+func WithBucketOptions(opts ...kv.Option) Option {
+	return func(e *Election) {
+		e.bucket = append(e.bucket, opts...)
+	}
+}
+
+// OnLeadershipChange registers a callback invoked with true when the candidate
+// is elected and with false when it resigns or loses the leadership.
+func OnLeadershipChange(fn func(leader bool)) Option {
+	return func(e *Election) {
+		e.callbacks = append(e.callbacks, fn)
+	}
+}
+
+// New returns the candidate identified by id in the election for the given name.
+func New(njs *events.NatsJetstream, name string, candidate registry.ControllerID, opts ...Option) (*Election, error) {
+	e := &Election{
+		name:      name,
+		candidate: candidate,
+		ttl:       defaultTTL,
+	}
+	for _, o := range opts {
+		o(e)
+	}
+
+	bucketOpts := append([]kv.Option{
+		kv.WithDescription(kvDescription),
+		kv.WithTTL(e.ttl),
+	}, e.bucket...)
+
+	handle, err := kv.CreateOrBindKVBucket(njs, BucketName, bucketOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	e.leases = lease.NewManagerFromKV(handle)
+
+	return e, nil
+}
+
+// Campaign blocks until the candidate is elected leader or the context is canceled.
+//
+// Once elected the leadership is held until Resign is called or it is lost,
+// the candidate may Campaign again after losing the leadership.
+func (e *Election) Campaign(ctx context.Context) error {
+	if e.IsLeader() {
+		return nil
+	}
+
+	l, err := e.leases.Acquire(ctx, e.name, e.candidate, e.ttl)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.lease = l
+	e.mu.Unlock()
+
+	e.notify(true)
+
+	go e.watch(l)
+
+	return nil
+}
+
+// watch reports the loss of leadership.
+func (e *Election) watch(l *lease.Lease) {
+	<-l.Lost()
+
+	e.mu.Lock()
+	if e.lease != l {
+		// resigned
+		e.mu.Unlock()
+		return
+	}
+	e.lease = nil
+	e.mu.Unlock()
+
+	e.notify(false)
+}
+
+// Resign gives up the leadership, ErrNotLeader is returned if the candidate is not the leader.
+func (e *Election) Resign() error {
+	e.mu.Lock()
+	l := e.lease
+	e.lease = nil
+	e.mu.Unlock()
+
+	if l == nil {
+		return fmt.Errorf("%w: election=%s", ErrNotLeader, e.name)
+	}
+
+	err := l.Release(context.Background())
+
+	e.notify(false)
+
+	return err
+}
+
+// IsLeader returns true while the candidate holds the leadership.
+func (e *Election) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.lease != nil
+}
+
+// Token returns the fencing token for the current term of leadership,
+// zero is returned when the candidate is not the leader.
+func (e *Election) Token() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lease == nil {
+		return 0
+	}
+
+	return e.lease.Token()
+}
+
+func (e *Election) notify(leader bool) {
+	for _, fn := range e.callbacks {
+		fn(leader)
+	}
+}
@@ -0,0 +1,98 @@
+//nolint:all
+package election
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-automata/rivets/events"
+	kvTest "github.com/metal-automata/rivets/events/internal/test"
+	"github.com/metal-automata/rivets/events/pkg/kv"
+	"github.com/metal-automata/rivets/events/registry"
+)
+
+type transitions struct {
+	mu     sync.Mutex
+	events []bool
+}
+
+func (tr *transitions) record(leader bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.events = append(tr.events, leader)
+}
+
+func (tr *transitions) get() []bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return append([]bool{}, tr.events...)
+}
+
+func TestElection(t *testing.T) {
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	var tr1, tr2 transitions
+
+	e1, err := New(evJS, "orchestrator", registry.GetID("orchestrator"),
+		WithTTL(time.Second), OnLeadershipChange(tr1.record))
+	require.NoError(t, err)
+	e2, err := New(evJS, "orchestrator", registry.GetID("orchestrator"),
+		WithTTL(time.Second), OnLeadershipChange(tr2.record))
+	require.NoError(t, err)
+
+	require.ErrorIs(t, e1.Resign(), ErrNotLeader)
+
+	ctx := context.Background()
+	require.NoError(t, e1.Campaign(ctx))
+	require.True(t, e1.IsLeader())
+	require.NotZero(t, e1.Token())
+	require.Equal(t, []bool{true}, tr1.get())
+
+	// campaigning again is a no-op for the leader
+	require.NoError(t, e1.Campaign(ctx))
+
+	// leadership is held beyond the TTL
+	campaignCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	require.ErrorIs(t, e2.Campaign(campaignCtx), context.DeadlineExceeded)
+	require.False(t, e2.IsLeader())
+	require.Zero(t, e2.Token())
+
+	// resigning hands over the leadership
+	elected := make(chan error)
+	go func() { elected <- e2.Campaign(ctx) }()
+
+	firstTerm := e1.Token()
+	require.NoError(t, e1.Resign())
+	require.False(t, e1.IsLeader())
+	require.Equal(t, []bool{true, false}, tr1.get())
+
+	select {
+	case err := <-elected:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the election")
+	}
+
+	require.True(t, e2.IsLeader())
+	require.Greater(t, e2.Token(), firstTerm)
+	require.Equal(t, []bool{true}, tr2.get())
+
+	// removing the leader key loses the leadership on the next renewal
+	handle, err := kv.CreateOrBindKVBucket(evJS, BucketName)
+	require.NoError(t, err)
+	require.NoError(t, handle.Delete("orchestrator"))
+
+	require.Eventually(t, func() bool { return !e2.IsLeader() }, 5*time.Second, 50*time.Millisecond)
+	require.Eventually(t, func() bool { return len(tr2.get()) == 2 }, time.Second, 10*time.Millisecond)
+	require.Equal(t, []bool{true, false}, tr2.get())
+	require.ErrorIs(t, e2.Resign(), ErrNotLeader)
+}
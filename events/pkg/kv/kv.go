@@ -40,6 +40,40 @@ func WithDescription(desc string) Option {
 	}
 }
 
+// WithHistory sets the number of historical values kept for each key, the maximum is 64.
+func WithHistory(history uint8) Option {
+	return func(c *nats.KeyValueConfig) {
+		c.History = history
+	}
+}
+
+func WithMaxValueSize(size int32) Option {
+	return func(c *nats.KeyValueConfig) {
+		c.MaxValueSize = size
+	}
+}
+
+func WithMaxBytes(size int64) Option {
+	return func(c *nats.KeyValueConfig) {
+		c.MaxBytes = size
+	}
+}
+
+// WithRepublish republishes changes to the bucket onto the given subjects,
+// see https://docs.nats.io/nats-concepts/jetstream/streams#republish
+func WithRepublish(republish *nats.RePublish) Option {
+	return func(c *nats.KeyValueConfig) {
+		c.RePublish = republish
+	}
+}
+
+// WithPlacement sets the cluster and server tags the bucket is to be placed on.
+func WithPlacement(placement *nats.Placement) Option {
+	return func(c *nats.KeyValueConfig) {
+		c.Placement = placement
+	}
+}
+
 // XXX: Not really sure we'd ever change this but...
 func WithStorageType(st nats.StorageType) Option {
 	return func(c *nats.KeyValueConfig) {
@@ -53,3 +53,80 @@ func TestCreateOrBind(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, kv2)
 }
+
+func TestExtendedOptions(t *testing.T) {
+	t.Parallel()
+	cfg := DefaultKVConfig("test")
+	republish := &nats.RePublish{Source: ">", Destination: "repub.>"}
+	placement := &nats.Placement{Cluster: "east"}
+	funcs := []Option{
+		WithHistory(10),
+		WithMaxValueSize(1024),
+		WithMaxBytes(1 << 20),
+		WithRepublish(republish),
+		WithPlacement(placement),
+	}
+	for _, f := range funcs {
+		f(cfg)
+	}
+	require.Equal(t, uint8(10), cfg.History)
+	require.Equal(t, int32(1024), cfg.MaxValueSize)
+	require.Equal(t, int64(1<<20), cfg.MaxBytes)
+	require.Equal(t, republish, cfg.RePublish)
+	require.Equal(t, placement, cfg.Placement)
+}
+
+func TestCreateOrUpdate(t *testing.T) {
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	_, err := CheckKVBucketConfig(evJS, "test-bucket")
+	require.ErrorIs(t, err, nats.ErrBucketNotFound)
+
+	kv, err := CreateOrUpdateKVBucket(evJS, "test-bucket", WithDescription("before"), WithTTL(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, kv)
+
+	drift, err := CheckKVBucketConfig(evJS, "test-bucket", WithDescription("before"), WithTTL(time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, drift)
+
+	// binding ignores the changed options
+	_, err = CreateOrBindKVBucket(evJS, "test-bucket", WithDescription("after"))
+	require.NoError(t, err)
+
+	desired := []Option{WithDescription("after"), WithTTL(time.Hour), WithHistory(5), WithMaxValueSize(1024)}
+	drift, err = CheckKVBucketConfig(evJS, "test-bucket", desired...)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []ConfigDrift{
+		{Field: "Description", Current: "before", Desired: "after"},
+		{Field: "TTL", Current: time.Minute, Desired: time.Hour},
+		{Field: "History", Current: uint8(1), Desired: uint8(5)},
+		{Field: "MaxValueSize", Current: int32(-1), Desired: int32(1024)},
+	}, drift)
+
+	_, err = CreateOrUpdateKVBucket(evJS, "test-bucket", desired...)
+	require.NoError(t, err)
+
+	cfg, err := BucketConfig(evJS, "test-bucket")
+	require.NoError(t, err)
+	require.Equal(t, "after", cfg.Description)
+	require.Equal(t, time.Hour, cfg.TTL)
+	require.Equal(t, uint8(5), cfg.History)
+	require.Equal(t, int32(1024), cfg.MaxValueSize)
+
+	drift, err = CheckKVBucketConfig(evJS, "test-bucket", desired...)
+	require.NoError(t, err)
+	require.Empty(t, drift)
+
+	drift, err = CheckKVBucketConfig(evJS, "test-bucket", append(desired, WithReplicas(3))...)
+	require.NoError(t, err)
+	require.Equal(t, []ConfigDrift{{Field: "Replicas", Current: 1, Desired: 3}}, drift)
+
+	_, err = CreateOrUpdateKVBucket(evJS, "test-bucket", append(desired, WithStorageType(nats.MemoryStorage))...)
+	require.ErrorIs(t, err, ErrBucketConfigImmutable)
+}
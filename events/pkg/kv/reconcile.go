@@ -0,0 +1,217 @@
+//nolint:wsl // useless
+package kv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/metal-automata/rivets/events"
+)
+
+const (
+	// the stream backing a KV bucket is named KV_<bucket>
+	kvStreamPrefix = "KV_"
+
+	// the duplicate window the nats client sets on KV streams
+	kvDuplicateWindow = 2 * time.Minute
+)
+
+var (
+	ErrBucketConfigImmutable = errors.New("KV bucket configuration cannot be changed")
+	ErrBucketReplicasUpdate  = errors.New("KV bucket replicas could not be updated")
+	ErrBucketUpdate          = errors.New("KV bucket configuration update failed")
+)
+
+// ConfigDrift is a difference between the configuration of an existing bucket
+// and the desired configuration.
+type ConfigDrift struct {
+	Field   string
+	Current any
+	Desired any
+}
+
+func (d ConfigDrift) String() string {
+	return fmt.Sprintf("%s: current=%v desired=%v", d.Field, d.Current, d.Desired)
+}
+
+// BucketConfig returns the configuration of an existing bucket.
+func BucketConfig(handle *events.NatsJetstream, bucketName string) (*nats.KeyValueConfig, error) {
+	info, err := events.AsNatsJetStreamContext(handle).StreamInfo(kvStreamPrefix + bucketName)
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		return nil, nats.ErrBucketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &nats.KeyValueConfig{
+		Bucket:       bucketName,
+		Description:  info.Config.Description,
+		MaxValueSize: info.Config.MaxMsgSize,
+		History:      uint8(info.Config.MaxMsgsPerSubject), //nolint:gosec // KV history is at most 64
+		TTL:          info.Config.MaxAge,
+		MaxBytes:     info.Config.MaxBytes,
+		Storage:      info.Config.Storage,
+		Replicas:     info.Config.Replicas,
+		Placement:    info.Config.Placement,
+		RePublish:    info.Config.RePublish,
+		Compression:  info.Config.Compression != nats.NoCompression,
+	}, nil
+}
+
+// normalize sets the defaults the nats client applies when creating a bucket,
+// so configurations can be compared.
+func normalize(cfg *nats.KeyValueConfig) *nats.KeyValueConfig {
+	n := *cfg
+	if n.History == 0 {
+		n.History = 1
+	}
+	if n.Replicas == 0 {
+		n.Replicas = 1
+	}
+	if n.MaxBytes == 0 {
+		n.MaxBytes = -1
+	}
+	if n.MaxValueSize == 0 {
+		n.MaxValueSize = -1
+	}
+	return &n
+}
+
+// BucketConfigDrift returns the differences between the current and desired bucket configuration.
+func BucketConfigDrift(current, desired *nats.KeyValueConfig) []ConfigDrift {
+	cur := normalize(current)
+	want := normalize(desired)
+
+	var drift []ConfigDrift
+	add := func(field string, c, d any) {
+		if !reflect.DeepEqual(c, d) {
+			drift = append(drift, ConfigDrift{Field: field, Current: c, Desired: d})
+		}
+	}
+
+	add("Description", cur.Description, want.Description)
+	add("MaxValueSize", cur.MaxValueSize, want.MaxValueSize)
+	add("History", cur.History, want.History)
+	add("TTL", cur.TTL, want.TTL)
+	add("MaxBytes", cur.MaxBytes, want.MaxBytes)
+	add("Storage", cur.Storage, want.Storage)
+	add("Replicas", cur.Replicas, want.Replicas)
+	add("Placement", derefOrZero(cur.Placement), derefOrZero(want.Placement))
+	add("RePublish", derefOrZero(cur.RePublish), derefOrZero(want.RePublish))
+	add("Compression", cur.Compression, want.Compression)
+
+	return drift
+}
+
+func derefOrZero[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+// CheckKVBucketConfig returns the differences between the configuration of the existing
+// bucket and the configuration the options describe.
+func CheckKVBucketConfig(handle *events.NatsJetstream, bucketName string, opts ...Option) ([]ConfigDrift, error) {
+	current, err := BucketConfig(handle, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := DefaultKVConfig(bucketName)
+	for _, o := range opts {
+		o(desired)
+	}
+
+	return BucketConfigDrift(current, desired), nil
+}
+
+// CreateOrUpdateKVBucket creates the bucket if it does not exist, an existing bucket is
+// updated to match the configuration the options describe.
+//
+// Changes to the storage type cannot be applied and return ErrBucketConfigImmutable,
+// replica changes the cluster rejects return ErrBucketReplicasUpdate.
+func CreateOrUpdateKVBucket(handle *events.NatsJetstream, bucketName string,
+	opts ...Option) (nats.KeyValue, error) {
+	drift, err := CheckKVBucketConfig(handle, bucketName, opts...)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		return CreateOrBindKVBucket(handle, bucketName, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(drift) > 0 {
+		if err := updateKVBucket(handle, bucketName, drift, opts...); err != nil {
+			return nil, err
+		}
+	}
+
+	return events.AsNatsJetStreamContext(handle).KeyValue(bucketName)
+}
+
+func updateKVBucket(handle *events.NatsJetstream, bucketName string, drift []ConfigDrift, opts ...Option) error {
+	var replicaDrift *ConfigDrift
+	for idx := range drift {
+		switch drift[idx].Field {
+		case "Storage":
+			return fmt.Errorf("%w: bucket=%s %s", ErrBucketConfigImmutable, bucketName, drift[idx].String())
+		case "Replicas":
+			replicaDrift = &drift[idx]
+		}
+	}
+
+	js := events.AsNatsJetStreamContext(handle)
+	info, err := js.StreamInfo(kvStreamPrefix + bucketName)
+	if err != nil {
+		return err
+	}
+
+	desired := DefaultKVConfig(bucketName)
+	for _, o := range opts {
+		o(desired)
+	}
+	desired = normalize(desired)
+
+	scfg := info.Config
+	scfg.Description = desired.Description
+	scfg.MaxMsgSize = desired.MaxValueSize
+	scfg.MaxMsgsPerSubject = int64(desired.History)
+	scfg.MaxAge = desired.TTL
+	scfg.MaxBytes = desired.MaxBytes
+	scfg.Replicas = desired.Replicas
+	scfg.Placement = desired.Placement
+	scfg.RePublish = desired.RePublish
+	scfg.Compression = nats.NoCompression
+	if desired.Compression {
+		scfg.Compression = nats.S2Compression
+	}
+
+	// the duplicate window cannot exceed the TTL
+	scfg.Duplicates = kvDuplicateWindow
+	if desired.TTL > 0 && desired.TTL < kvDuplicateWindow {
+		scfg.Duplicates = desired.TTL
+	}
+
+	updated, err := js.UpdateStream(&scfg)
+	if err != nil {
+		if replicaDrift != nil {
+			return fmt.Errorf("%w: bucket=%s %s: %s", ErrBucketReplicasUpdate, bucketName, replicaDrift.String(), err.Error())
+		}
+		return fmt.Errorf("%w: bucket=%s: %s", ErrBucketUpdate, bucketName, err.Error())
+	}
+
+	// the server may accept the update without applying the replica change
+	if updated.Config.Replicas != desired.Replicas {
+		return fmt.Errorf("%w: bucket=%s replicas current=%d desired=%d",
+			ErrBucketReplicasUpdate, bucketName, updated.Config.Replicas, desired.Replicas)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+//nolint:wsl // useless
+package kv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	defaultMaxUpdateRetries = 10
+)
+
+var (
+	ErrBadValue           = errors.New("bad KV value")
+	ErrUpdateRetriesSpent = errors.New("KV update retries exhausted")
+)
+
+// Codec serializes and deserializes the values stored in a Typed bucket.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is the default Codec for Typed buckets.
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// Entry is a value retrieved from a Typed bucket along with its revision.
+type Entry[T any] struct {
+	Key      string
+	Value    T
+	Revision uint64
+	Created  time.Time
+}
+
+// Typed wraps a nats.KeyValue to store and retrieve values of type T.
+type Typed[T any] struct {
+	kv               nats.KeyValue
+	codec            Codec
+	maxUpdateRetries int
+}
+
+type TypedOption[T any] func(t *Typed[T])
+
+// WithCodec sets the Codec used to serialize values, JSONCodec is used by default.
+func WithCodec[T any](c Codec) TypedOption[T] {
+	return func(t *Typed[T]) {
+		t.codec = c
+	}
+}
+
+// WithMaxUpdateRetries sets the number of times Update retries on a revision conflict.
+func WithMaxUpdateRetries[T any](n int) TypedOption[T] {
+	return func(t *Typed[T]) {
+		t.maxUpdateRetries = n
+	}
+}
+
+// NewTyped returns a Typed bucket for the given nats.KeyValue, see CreateOrBindKVBucket.
+func NewTyped[T any](kv nats.KeyValue, opts ...TypedOption[T]) *Typed[T] {
+	t := &Typed[T]{
+		kv:               kv,
+		codec:            JSONCodec{},
+		maxUpdateRetries: defaultMaxUpdateRetries,
+	}
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+// KeyValue exposes the underlying nats.KeyValue
+func (t *Typed[T]) KeyValue() nats.KeyValue {
+	return t.kv
+}
+
+// Get returns the value for the key, a missing key results in nats.ErrKeyNotFound.
+func (t *Typed[T]) Get(key string) (*Entry[T], error) {
+	kve, err := t.kv.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return t.decode(kve)
+}
+
+func (t *Typed[T]) decode(kve nats.KeyValueEntry) (*Entry[T], error) {
+	entry := &Entry[T]{
+		Key:      kve.Key(),
+		Revision: kve.Revision(),
+		Created:  kve.Created(),
+	}
+	if err := t.codec.Unmarshal(kve.Value(), &entry.Value); err != nil {
+		return nil, fmt.Errorf("%w: key=%s: %s", ErrBadValue, kve.Key(), err.Error())
+	}
+	return entry, nil
+}
+
+// Put sets the value for the key regardless of its current revision.
+func (t *Typed[T]) Put(key string, value T) (uint64, error) {
+	byt, err := t.codec.Marshal(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: key=%s: %s", ErrBadValue, key, err.Error())
+	}
+	return t.kv.Put(key, byt)
+}
+
+// Create sets the value for the key only if it does not exist, an existing key
+// results in nats.ErrKeyExists.
+func (t *Typed[T]) Create(key string, value T) (uint64, error) {
+	byt, err := t.codec.Marshal(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: key=%s: %s", ErrBadValue, key, err.Error())
+	}
+	return t.kv.Create(key, byt)
+}
+
+// UpdateRevision sets the value for the key only if its current revision matches
+// the one given, on a mismatch nats.ErrKeyExists is returned.
+func (t *Typed[T]) UpdateRevision(key string, value T, revision uint64) (uint64, error) {
+	byt, err := t.codec.Marshal(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: key=%s: %s", ErrBadValue, key, err.Error())
+	}
+	return t.kv.Update(key, byt, revision)
+}
+
+// Delete removes the key.
+func (t *Typed[T]) Delete(key string) error {
+	return t.kv.Delete(key)
+}
+
+// Update applies fn to the current value of the key and writes it back with a
+// compare-and-swap on the revision, retrying from a fresh read when another
+// writer updated the key in between.
+//
+// If the key does not exist fn is called with the zero value and the key is created.
+// An error returned by fn aborts the update and is returned as is.
+func (t *Typed[T]) Update(key string, fn func(*T) error) (*Entry[T], error) {
+	for attempt := 0; attempt <= t.maxUpdateRetries; attempt++ {
+		var value T
+		var revision uint64
+
+		current, err := t.Get(key)
+		switch {
+		case err == nil:
+			value = current.Value
+			revision = current.Revision
+		case errors.Is(err, nats.ErrKeyNotFound):
+		default:
+			return nil, err
+		}
+
+		if err := fn(&value); err != nil {
+			return nil, err
+		}
+
+		if revision == 0 {
+			revision, err = t.Create(key, value)
+		} else {
+			revision, err = t.UpdateRevision(key, value, revision)
+		}
+
+		switch {
+		case err == nil:
+			return &Entry[T]{Key: key, Value: value, Revision: revision, Created: time.Now()}, nil
+		case errors.Is(err, nats.ErrKeyExists):
+			continue // lost the race to another writer, try again
+		default:
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("%w: key=%s", ErrUpdateRetriesSpent, key)
+}
@@ -0,0 +1,130 @@
+//nolint:all
+package kv
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-automata/rivets/events"
+	kvTest "github.com/metal-automata/rivets/events/internal/test"
+)
+
+type counter struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// intCodec stores ints as decimal strings
+type intCodec struct{}
+
+func (intCodec) Marshal(v any) ([]byte, error) {
+	return []byte(strconv.Itoa(v.(int))), nil
+}
+
+func (intCodec) Unmarshal(data []byte, v any) error {
+	i, err := strconv.Atoi(string(data))
+	if err != nil {
+		return err
+	}
+	*(v.(*int)) = i
+	return nil
+}
+
+func TestTyped(t *testing.T) {
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	handle, err := CreateOrBindKVBucket(evJS, "typed")
+	require.NoError(t, err)
+
+	typed := NewTyped[counter](handle)
+
+	_, err = typed.Get("a")
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+
+	rev, err := typed.Create("a", counter{Name: "a"})
+	require.NoError(t, err)
+
+	_, err = typed.Create("a", counter{Name: "a"})
+	require.ErrorIs(t, err, nats.ErrKeyExists)
+
+	entry, err := typed.Get("a")
+	require.NoError(t, err)
+	require.Equal(t, rev, entry.Revision)
+	require.Equal(t, "a", entry.Value.Name)
+
+	_, err = typed.UpdateRevision("a", counter{Name: "a", Count: 1}, rev+10)
+	require.ErrorIs(t, err, nats.ErrKeyExists)
+
+	rev2, err := typed.Put("a", counter{Name: "a", Count: 5})
+	require.NoError(t, err)
+	require.Greater(t, rev2, rev)
+
+	// concurrent updates don't lose writes
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := typed.Update("a", func(c *counter) error {
+				c.Count++
+				return nil
+			})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	entry, err = typed.Get("a")
+	require.NoError(t, err)
+	require.Equal(t, 15, entry.Value.Count)
+
+	// update on a missing key creates it
+	entry, err = typed.Update("b", func(c *counter) error {
+		c.Name = "b"
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "b", entry.Value.Name)
+
+	// errors from the update function abort the update
+	errAbort := errors.New("abort")
+	_, err = typed.Update("b", func(c *counter) error {
+		c.Count = 100
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+	entry, err = typed.Get("b")
+	require.NoError(t, err)
+	require.Equal(t, 0, entry.Value.Count)
+
+	require.NoError(t, typed.Delete("b"))
+	_, err = typed.Get("b")
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+
+	// undecodable values
+	_, err = handle.Put("junk", []byte("not json"))
+	require.NoError(t, err)
+	_, err = typed.Get("junk")
+	require.ErrorIs(t, err, ErrBadValue)
+
+	// custom codec
+	ints := NewTyped[int](handle, WithCodec[int](intCodec{}))
+	_, err = ints.Put("int", 42)
+	require.NoError(t, err)
+	raw, err := handle.Get("int")
+	require.NoError(t, err)
+	require.Equal(t, []byte("42"), raw.Value())
+	got, err := ints.Get("int")
+	require.NoError(t, err)
+	require.Equal(t, 42, got.Value)
+}
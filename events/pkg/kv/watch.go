@@ -0,0 +1,165 @@
+//nolint:wsl // useless
+package kv
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Operation identifies the kind of change a WatchEvent reports.
+type Operation string
+
+const (
+	// OpPut is a new value for the key.
+	OpPut Operation = "put"
+
+	// OpDelete is a removal of the key.
+	OpDelete Operation = "delete"
+
+	// OpPurge is a removal of the key along with its history.
+	OpPurge Operation = "purge"
+
+	// OpSynced is sent once the initial values have been delivered,
+	// it carries no key or value.
+	OpSynced Operation = "synced"
+)
+
+// WatchEvent is a change to a key in a Typed bucket.
+//
+// Value is only set for OpPut events, Err is set when the value could not be decoded.
+type WatchEvent[T any] struct {
+	Op       Operation
+	Key      string
+	Value    T
+	Revision uint64
+	Created  time.Time
+	Err      error
+}
+
+type watchConfig struct {
+	updatesOnly    bool
+	includeHistory bool
+	ignoreDeletes  bool
+}
+
+type WatchOption func(c *watchConfig)
+
+// WatchUpdatesOnly skips the initial values, only changes made after the watch is
+// started are sent. By default the current values are sent first, followed by OpSynced.
+func WatchUpdatesOnly() WatchOption {
+	return func(c *watchConfig) {
+		c.updatesOnly = true
+	}
+}
+
+// WatchIncludeHistory sends all the values retained in the bucket history for the
+// matching keys instead of only the latest.
+func WatchIncludeHistory() WatchOption {
+	return func(c *watchConfig) {
+		c.includeHistory = true
+	}
+}
+
+// WatchIgnoreDeletes skips delete and purge events.
+func WatchIgnoreDeletes() WatchOption {
+	return func(c *watchConfig) {
+		c.ignoreDeletes = true
+	}
+}
+
+func (c *watchConfig) natsOpts(ctx context.Context) []nats.WatchOpt {
+	opts := []nats.WatchOpt{nats.Context(ctx)}
+	if c.updatesOnly {
+		opts = append(opts, nats.UpdatesOnly())
+	}
+	if c.includeHistory {
+		opts = append(opts, nats.IncludeHistory())
+	}
+	if c.ignoreDeletes {
+		opts = append(opts, nats.IgnoreDeletes())
+	}
+	return opts
+}
+
+// Watch sends changes to the keys matching any of the given patterns on the returned channel,
+// patterns may include NATS subject wildcards - fc13.* or fc13.>
+// No patterns watches every key in the bucket.
+//
+// The watch is stopped and the channel closed when the context is canceled.
+func (t *Typed[T]) Watch(ctx context.Context, patterns []string, opts ...WatchOption) (<-chan WatchEvent[T], error) {
+	cfg := &watchConfig{}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	if len(patterns) == 0 {
+		patterns = []string{">"}
+	}
+
+	watcher, err := t.kv.WatchFiltered(patterns, cfg.natsOpts(ctx)...)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan WatchEvent[T])
+	go func() {
+		defer close(ch)
+		//nolint:errcheck // nothing useful to do with this error
+		defer watcher.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case kve, ok := <-watcher.Updates():
+				if !ok {
+					return
+				}
+
+				// nil marks the end of the initial values
+				if kve == nil && cfg.updatesOnly {
+					continue
+				}
+
+				select {
+				case ch <- t.watchEvent(kve):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (t *Typed[T]) watchEvent(kve nats.KeyValueEntry) WatchEvent[T] {
+	if kve == nil {
+		return WatchEvent[T]{Op: OpSynced}
+	}
+
+	event := WatchEvent[T]{
+		Key:      kve.Key(),
+		Revision: kve.Revision(),
+		Created:  kve.Created(),
+	}
+
+	switch kve.Operation() {
+	case nats.KeyValueDelete:
+		event.Op = OpDelete
+	case nats.KeyValuePurge:
+		event.Op = OpPurge
+	default:
+		event.Op = OpPut
+		entry, err := t.decode(kve)
+		if err != nil {
+			event.Err = err
+		} else {
+			event.Value = entry.Value
+		}
+	}
+
+	return event
+}
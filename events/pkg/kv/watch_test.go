@@ -0,0 +1,104 @@
+//nolint:all
+package kv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-automata/rivets/events"
+	kvTest "github.com/metal-automata/rivets/events/internal/test"
+)
+
+func nextEvent[T any](t *testing.T, ch <-chan WatchEvent[T]) WatchEvent[T] {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		require.True(t, ok, "watch channel closed")
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch event")
+	}
+	return WatchEvent[T]{}
+}
+
+func TestTypedWatch(t *testing.T) {
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	handle, err := CreateOrBindKVBucket(evJS, "watched", func(c *nats.KeyValueConfig) { c.History = 5 })
+	require.NoError(t, err)
+
+	typed := NewTyped[counter](handle)
+
+	_, err = typed.Put("fc13.a", counter{Name: "a", Count: 1})
+	require.NoError(t, err)
+	_, err = typed.Put("fc13.a", counter{Name: "a", Count: 2})
+	require.NoError(t, err)
+	_, err = typed.Put("fc42.b", counter{Name: "b"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// initial values then updates
+	ch, err := typed.Watch(ctx, []string{"fc13.*"})
+	require.NoError(t, err)
+
+	ev := nextEvent(t, ch)
+	require.Equal(t, OpPut, ev.Op)
+	require.Equal(t, "fc13.a", ev.Key)
+	require.Equal(t, 2, ev.Value.Count)
+	require.Equal(t, OpSynced, nextEvent(t, ch).Op)
+
+	_, err = typed.Put("fc42.b", counter{Name: "b", Count: 1}) // filtered
+	require.NoError(t, err)
+	_, err = handle.Put("fc13.c", []byte("junk"))
+	require.NoError(t, err)
+	require.NoError(t, typed.Delete("fc13.a"))
+	require.NoError(t, handle.Purge("fc13.c"))
+
+	ev = nextEvent(t, ch)
+	require.Equal(t, OpPut, ev.Op)
+	require.Equal(t, "fc13.c", ev.Key)
+	require.ErrorIs(t, ev.Err, ErrBadValue)
+
+	ev = nextEvent(t, ch)
+	require.Equal(t, OpDelete, ev.Op)
+	require.Equal(t, "fc13.a", ev.Key)
+
+	ev = nextEvent(t, ch)
+	require.Equal(t, OpPurge, ev.Op)
+	require.Equal(t, "fc13.c", ev.Key)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, open := <-ch
+		return !open
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// include history
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	ch, err = typed.Watch(ctx2, []string{"fc42.*"}, WatchIncludeHistory())
+	require.NoError(t, err)
+	require.Equal(t, 0, nextEvent(t, ch).Value.Count)
+	require.Equal(t, 1, nextEvent(t, ch).Value.Count)
+	require.Equal(t, OpSynced, nextEvent(t, ch).Op)
+
+	// updates only
+	ch, err = typed.Watch(ctx2, []string{"fc42.*"}, WatchUpdatesOnly())
+	require.NoError(t, err)
+	_, err = typed.Put("fc42.b", counter{Name: "b", Count: 3})
+	require.NoError(t, err)
+	ev = nextEvent(t, ch)
+	require.Equal(t, OpPut, ev.Op)
+	require.Equal(t, 3, ev.Value.Count)
+}
@@ -0,0 +1,321 @@
+// Package lease provides distributed leases on a NATS KV bucket, used to ensure
+// a single controller works on a server at a time.
+//
+// Each lease carries a fencing token, the KV revision at which it was acquired.
+// Tokens increase monotonically, a later holder of a lease always has a larger
+// token than any previous holder.
+//
+// Lease expiry is determined from the wall clock of the controllers, the TTL
+// should be large compared to the expected clock skew.
+//
+//nolint:wsl // useless
+package lease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+
+	"github.com/metal-automata/rivets/events"
+	"github.com/metal-automata/rivets/events/pkg/kv"
+	"github.com/metal-automata/rivets/events/registry"
+)
+
+var (
+	BucketName    = "server-leases"
+	kvDescription = "leases held by controllers on servers"
+
+	// interval between attempts to acquire a held lease
+	acquireRetryInterval = 500 * time.Millisecond
+
+	ErrLeaseHeld    = errors.New("lease is held by another controller")
+	ErrLeaseLost    = errors.New("lease lost")
+	ErrLeaseInvalid = errors.New("invalid lease parameters")
+)
+
+// record is the value stored in the KV for a held lease.
+type record struct {
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func (r *record) expired() bool {
+	return time.Now().After(r.ExpiresAt)
+}
+
+// Manager acquires leases on the KV bucket.
+type Manager struct {
+	kv *kv.Typed[record]
+}
+
+// NewManager returns a Manager on the lease bucket, creating the bucket if required.
+func NewManager(njs *events.NatsJetstream, opts ...kv.Option) (*Manager, error) {
+	opts = append([]kv.Option{kv.WithDescription(kvDescription)}, opts...)
+	handle, err := kv.CreateOrBindKVBucket(njs, BucketName, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewManagerFromKV(handle), nil
+}
+
+// NewManagerFromKV returns a Manager for leases on the given KV bucket.
+func NewManagerFromKV(handle nats.KeyValue) *Manager {
+	return &Manager{kv: kv.NewTyped[record](handle)}
+}
+
+// ServerKey returns the lease key for a server.
+func ServerKey(serverID uuid.UUID) string {
+	return serverID.String()
+}
+
+type acquireConfig struct {
+	autoRenew     bool
+	renewInterval time.Duration
+}
+
+type AcquireOption func(c *acquireConfig)
+
+// WithoutAutoRenew disables the background renewal of the lease, the holder is
+// responsible for calling Renew before the TTL expires.
+func WithoutAutoRenew() AcquireOption {
+	return func(c *acquireConfig) {
+		c.autoRenew = false
+	}
+}
+
+// WithRenewInterval sets the interval at which the lease is renewed and checked for expiry,
+// the default is a third of the TTL.
+func WithRenewInterval(d time.Duration) AcquireOption {
+	return func(c *acquireConfig) {
+		c.renewInterval = d
+	}
+}
+
+// Acquire blocks until the lease on the key is acquired for the holder or the context is canceled.
+func (m *Manager) Acquire(ctx context.Context, key string, holder registry.ControllerID,
+	ttl time.Duration, opts ...AcquireOption) (*Lease, error) {
+	for {
+		l, expiresAt, err := m.tryAcquire(key, holder, ttl, opts...)
+		if !errors.Is(err, ErrLeaseHeld) {
+			return l, err
+		}
+
+		wait := acquireRetryInterval
+		if until := time.Until(expiresAt); until > 0 && until < wait {
+			wait = until
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// AcquireServer blocks until the lease on the server is acquired for the holder or the context is canceled.
+func (m *Manager) AcquireServer(ctx context.Context, serverID uuid.UUID, holder registry.ControllerID,
+	ttl time.Duration, opts ...AcquireOption) (*Lease, error) {
+	return m.Acquire(ctx, ServerKey(serverID), holder, ttl, opts...)
+}
+
+// TryAcquire acquires the lease on the key for the holder, ErrLeaseHeld is returned when
+// another holder has a lease on the key that has not expired.
+func (m *Manager) TryAcquire(key string, holder registry.ControllerID, ttl time.Duration,
+	opts ...AcquireOption) (*Lease, error) {
+	l, _, err := m.tryAcquire(key, holder, ttl, opts...)
+	return l, err
+}
+
+func (m *Manager) tryAcquire(key string, holder registry.ControllerID, ttl time.Duration,
+	opts ...AcquireOption) (*Lease, time.Time, error) {
+	var zt time.Time
+	if ttl <= 0 {
+		return nil, zt, fmt.Errorf("%w: ttl must be positive", ErrLeaseInvalid)
+	}
+
+	cfg := &acquireConfig{autoRenew: true, renewInterval: ttl / 3}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	now := time.Now()
+	rec := record{
+		Holder:     holder.String(),
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+
+	current, err := m.kv.Get(key)
+	var rev uint64
+	switch {
+	case errors.Is(err, nats.ErrKeyNotFound):
+		rev, err = m.kv.Create(key, rec)
+	case err != nil:
+		return nil, zt, err
+	case current.Value.expired():
+		rev, err = m.kv.UpdateRevision(key, rec, current.Revision)
+	default:
+		return nil, current.Value.ExpiresAt, fmt.Errorf("%w: key=%s holder=%s", ErrLeaseHeld, key, current.Value.Holder)
+	}
+
+	if errors.Is(err, nats.ErrKeyExists) {
+		// another holder got in first
+		return nil, zt, fmt.Errorf("%w: key=%s", ErrLeaseHeld, key)
+	}
+	if err != nil {
+		return nil, zt, err
+	}
+
+	l := &Lease{
+		kv:       m.kv,
+		key:      key,
+		holder:   holder,
+		ttl:      ttl,
+		token:    rev,
+		revision: rev,
+		record:   rec,
+		lost:     make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+
+	go l.run(cfg)
+
+	return l, zt, nil
+}
+
+// Lease is a lease held on a key.
+type Lease struct {
+	kv       *kv.Typed[record]
+	key      string
+	holder   registry.ControllerID
+	ttl      time.Duration
+	token    uint64
+	mu       sync.Mutex
+	revision uint64
+	record   record
+	released bool
+	lost     chan struct{}
+	lostOnce sync.Once
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// Key returns the key the lease is held on.
+func (l *Lease) Key() string {
+	return l.key
+}
+
+// Holder returns the controller holding the lease.
+func (l *Lease) Holder() registry.ControllerID {
+	return l.holder
+}
+
+// Token returns the fencing token for the lease.
+func (l *Lease) Token() uint64 {
+	return l.token
+}
+
+// ExpiresAt returns the time at which the lease expires unless renewed.
+func (l *Lease) ExpiresAt() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.record.ExpiresAt
+}
+
+// Lost returns a channel that is closed when the lease is lost, either
+// because it expired or another holder acquired it.
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Renew extends the lease by its TTL, ErrLeaseLost is returned if the lease
+// is no longer held.
+func (l *Lease) Renew(_ context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.released {
+		return fmt.Errorf("%w: key=%s lease released", ErrLeaseLost, l.key)
+	}
+
+	rec := l.record
+	rec.ExpiresAt = time.Now().Add(l.ttl)
+
+	rev, err := l.kv.UpdateRevision(l.key, rec, l.revision)
+	if errors.Is(err, nats.ErrKeyExists) || errors.Is(err, nats.ErrKeyNotFound) {
+		l.markLost()
+		return fmt.Errorf("%w: key=%s", ErrLeaseLost, l.key)
+	}
+	if err != nil {
+		return err
+	}
+
+	l.revision = rev
+	l.record = rec
+
+	return nil
+}
+
+// Release gives up the lease, the key is left untouched if the lease was lost.
+func (l *Lease) Release(_ context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.released {
+		return nil
+	}
+	l.released = true
+
+	err := l.kv.KeyValue().Delete(l.key, nats.LastRevision(l.revision))
+	if errors.Is(err, nats.ErrKeyExists) {
+		return fmt.Errorf("%w: key=%s", ErrLeaseLost, l.key)
+	}
+
+	return err
+}
+
+func (l *Lease) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
+
+// run renews the lease in the background and signals when it is lost.
+func (l *Lease) run(cfg *acquireConfig) {
+	interval := cfg.renewInterval
+	if interval <= 0 {
+		interval = l.ttl / 3
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-l.lost:
+			return
+		case <-ticker.C:
+			if cfg.autoRenew {
+				// transient errors are retried on the next tick, until the lease expires
+				if err := l.Renew(context.Background()); errors.Is(err, ErrLeaseLost) {
+					return
+				}
+			}
+
+			if time.Now().After(l.ExpiresAt()) {
+				l.markLost()
+				return
+			}
+		}
+	}
+}
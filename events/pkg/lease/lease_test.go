@@ -0,0 +1,113 @@
+//nolint:all
+package lease
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-automata/rivets/events"
+	kvTest "github.com/metal-automata/rivets/events/internal/test"
+	"github.com/metal-automata/rivets/events/registry"
+)
+
+func TestLease(t *testing.T) {
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	mgr, err := NewManager(evJS)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	serverID := uuid.New()
+	flasher := registry.GetID("flasher")
+	bioscfg := registry.GetID("bioscfg")
+
+	_, err = mgr.TryAcquire(ServerKey(serverID), flasher, 0)
+	require.ErrorIs(t, err, ErrLeaseInvalid)
+
+	l1, err := mgr.AcquireServer(ctx, serverID, flasher, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, serverID.String(), l1.Key())
+	require.Equal(t, flasher, l1.Holder())
+
+	_, err = mgr.TryAcquire(ServerKey(serverID), bioscfg, time.Minute)
+	require.ErrorIs(t, err, ErrLeaseHeld)
+
+	// acquire blocks while the lease is held
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = mgr.AcquireServer(waitCtx, serverID, bioscfg, time.Minute)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	expires := l1.ExpiresAt()
+	require.NoError(t, l1.Renew(ctx))
+	require.True(t, l1.ExpiresAt().After(expires))
+	require.Equal(t, l1.Token(), l1.Token(), "token is stable across renewals")
+
+	require.NoError(t, l1.Release(ctx))
+	require.ErrorIs(t, l1.Renew(ctx), ErrLeaseLost)
+
+	l2, err := mgr.AcquireServer(ctx, serverID, bioscfg, time.Minute)
+	require.NoError(t, err)
+	require.Greater(t, l2.Token(), l1.Token())
+	require.NoError(t, l2.Release(ctx))
+
+	select {
+	case <-l1.Lost():
+		t.Fatal("released lease reported lost")
+	default:
+	}
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	mgr, err := NewManager(evJS)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	key := ServerKey(uuid.New())
+	flasher := registry.GetID("flasher")
+	bioscfg := registry.GetID("bioscfg")
+
+	// auto-renewal keeps the lease past its TTL
+	l1, err := mgr.Acquire(ctx, key, flasher, 300*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(time.Second)
+	_, err = mgr.TryAcquire(key, bioscfg, time.Minute)
+	require.ErrorIs(t, err, ErrLeaseHeld)
+	require.NoError(t, l1.Release(ctx))
+
+	// without renewal the lease expires and is taken over
+	l2, err := mgr.Acquire(ctx, key, flasher, 300*time.Millisecond, WithoutAutoRenew())
+	require.NoError(t, err)
+
+	l3, err := mgr.Acquire(ctx, key, bioscfg, time.Minute)
+	require.NoError(t, err)
+	require.Greater(t, l3.Token(), l2.Token())
+
+	select {
+	case <-l2.Lost():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the lease to be lost")
+	}
+
+	require.ErrorIs(t, l2.Renew(ctx), ErrLeaseLost)
+	require.ErrorIs(t, l2.Release(ctx), ErrLeaseLost)
+
+	// the lost lease release left the new holder in place
+	_, err = mgr.TryAcquire(key, flasher, time.Minute)
+	require.ErrorIs(t, err, ErrLeaseHeld)
+	require.NoError(t, l3.Release(ctx))
+}
@@ -0,0 +1,208 @@
+// Package objstore wraps the NATS JetStream object store for staging
+// artifacts like firmware binaries and BIOS configuration files in NATS.
+//
+//nolint:wsl // useless
+package objstore
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/metal-automata/rivets/events"
+)
+
+var (
+	ErrChecksumMismatch = errors.New("object SHA-256 checksum mismatch")
+	ErrBadChecksum      = errors.New("bad SHA-256 checksum")
+)
+
+// DefaultObjectStoreConfig returns a configuration with "mostly sane" defaults.
+// Override with the following Option functions
+func DefaultObjectStoreConfig(bucketName string) *nats.ObjectStoreConfig {
+	return &nats.ObjectStoreConfig{
+		Bucket: bucketName,
+		// as with the KV, the zero-value for StorageType gives us file storage
+	}
+}
+
+type Option func(c *nats.ObjectStoreConfig)
+
+func WithTTL(d time.Duration) Option {
+	return func(c *nats.ObjectStoreConfig) {
+		c.TTL = d
+	}
+}
+
+func WithReplicas(replicas int) Option {
+	return func(c *nats.ObjectStoreConfig) {
+		c.Replicas = replicas
+	}
+}
+
+func WithDescription(desc string) Option {
+	return func(c *nats.ObjectStoreConfig) {
+		c.Description = desc
+	}
+}
+
+func WithStorageType(st nats.StorageType) Option {
+	return func(c *nats.ObjectStoreConfig) {
+		c.Storage = st
+	}
+}
+
+func CreateOrBindObjectStore(handle *events.NatsJetstream, bucketName string,
+	opts ...Option) (nats.ObjectStore, error) {
+	js := events.AsNatsJetStreamContext(handle)
+	store, err := js.ObjectStore(bucketName)
+	if errors.Is(err, nats.ErrStreamNotFound) || errors.Is(err, nats.ErrBucketNotFound) {
+		cfg := DefaultObjectStoreConfig(bucketName)
+		for _, o := range opts {
+			o(cfg)
+		}
+		return js.CreateObjectStore(cfg)
+	}
+	return store, err
+}
+
+// Put streams the contents of the reader into the store under the given name.
+//
+// When sha256Hex is set the digest of the stored object is compared against it,
+// on a mismatch the object is removed and ErrChecksumMismatch is returned.
+func Put(ctx context.Context, store nats.ObjectStore, name string, r io.Reader,
+	sha256Hex string) (*nats.ObjectInfo, error) {
+	info, err := store.Put(&nats.ObjectMeta{Name: name}, r, nats.Context(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if sha256Hex == "" {
+		return info, nil
+	}
+
+	if err := verify(info, sha256Hex); err != nil {
+		if errDel := store.Delete(name); errDel != nil {
+			return nil, fmt.Errorf("%w: removing object failed: %s", err, errDel.Error())
+		}
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// Get streams the named object from the store into the writer.
+//
+// The object data is verified against its stored SHA-256 digest as it is read,
+// a corrupt object results in a nats.ErrDigestMismatch error. When sha256Hex is
+// set the stored digest is compared against it before any data is written.
+func Get(ctx context.Context, store nats.ObjectStore, name string, w io.Writer,
+	sha256Hex string) (*nats.ObjectInfo, error) {
+	if sha256Hex != "" {
+		info, err := store.GetInfo(name, nats.Context(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := verify(info, sha256Hex); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := store.Get(name, nats.Context(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	if _, err := io.Copy(w, result); err != nil {
+		return nil, err
+	}
+
+	return result.Info()
+}
+
+// SHA256Hex returns the hex encoded SHA-256 digest of the object.
+func SHA256Hex(info *nats.ObjectInfo) (string, error) {
+	sum, err := nats.DecodeObjectDigest(info.Digest)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sum), nil
+}
+
+func verify(info *nats.ObjectInfo, sha256Hex string) error {
+	want, err := hex.DecodeString(strings.TrimSpace(sha256Hex))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBadChecksum, err.Error())
+	}
+
+	got, err := SHA256Hex(info)
+	if err != nil {
+		return err
+	}
+
+	if got != hex.EncodeToString(want) {
+		return fmt.Errorf("%w: object=%s expected=%s got=%s", ErrChecksumMismatch, info.Name, sha256Hex, got)
+	}
+
+	return nil
+}
+
+// List returns information on all the objects in the store, an empty store
+// results in an empty list.
+func List(ctx context.Context, store nats.ObjectStore) ([]*nats.ObjectInfo, error) {
+	objects, err := store.List(nats.Context(ctx))
+	if errors.Is(err, nats.ErrNoObjectsFound) {
+		return []*nats.ObjectInfo{}, nil
+	}
+	return objects, err
+}
+
+// Watch returns a channel on which object information is sent as objects
+// are added, updated or deleted in the store. The existing objects are sent
+// first, unless nats.UpdatesOnly() is included in the options.
+//
+// The watch is stopped and the channel closed when the context is canceled.
+func Watch(ctx context.Context, store nats.ObjectStore, opts ...nats.WatchOpt) (<-chan *nats.ObjectInfo, error) {
+	watcher, err := store.Watch(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *nats.ObjectInfo)
+	go func() {
+		defer close(ch)
+		//nolint:errcheck // nothing useful to do with this error
+		defer watcher.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case info, ok := <-watcher.Updates():
+				if !ok {
+					return
+				}
+				// nil marks the end of the initial values
+				if info == nil {
+					continue
+				}
+				select {
+				case ch <- info:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
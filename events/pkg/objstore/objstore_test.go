@@ -0,0 +1,161 @@
+//nolint:all
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-automata/rivets/events"
+	kvTest "github.com/metal-automata/rivets/events/internal/test"
+)
+
+func TestDefaultConfigAndOptions(t *testing.T) {
+	t.Parallel()
+	cfg := DefaultObjectStoreConfig("test")
+	require.NotNil(t, cfg)
+	require.Equal(t, "test", cfg.Bucket)
+	require.Equal(t, 0, cfg.Replicas)
+	require.Equal(t, time.Duration(0), cfg.TTL)
+	require.Equal(t, "", cfg.Description)
+	funcs := []Option{
+		WithTTL(2 * time.Minute),
+		WithStorageType(nats.MemoryStorage),
+		WithReplicas(3),
+		WithDescription("test"),
+	}
+	for _, f := range funcs {
+		f(cfg)
+	}
+	require.Equal(t, "test", cfg.Description)
+	require.Equal(t, 3, cfg.Replicas)
+	require.Equal(t, nats.MemoryStorage, cfg.Storage)
+	require.Equal(t, 2*time.Minute, cfg.TTL)
+}
+
+func TestObjectStore(t *testing.T) {
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	store, err := CreateOrBindObjectStore(evJS, "firmware", WithDescription("staged firmware"))
+	require.NoError(t, err)
+	require.NotNil(t, store)
+
+	// bind only-path
+	store2, err := CreateOrBindObjectStore(evJS, "firmware")
+	require.NoError(t, err)
+	require.NotNil(t, store2)
+
+	ctx := context.Background()
+
+	objects, err := List(ctx, store)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates, err := Watch(watchCtx, store)
+	require.NoError(t, err)
+
+	payload := bytes.Repeat([]byte("firmware"), 64*1024)
+	sum := sha256.Sum256(payload)
+	checksum := hex.EncodeToString(sum[:])
+
+	info, err := Put(ctx, store, "dell/bios.bin", bytes.NewReader(payload), checksum)
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(payload)), info.Size)
+
+	got, err := SHA256Hex(info)
+	require.NoError(t, err)
+	require.Equal(t, checksum, got)
+
+	select {
+	case update := <-updates:
+		require.Equal(t, "dell/bios.bin", update.Name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch update")
+	}
+
+	// checksum mismatch on put removes the object
+	_, err = Put(ctx, store, "bogus.bin", bytes.NewReader([]byte("bogus")), checksum)
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+	_, err = store.GetInfo("bogus.bin")
+	require.ErrorIs(t, err, nats.ErrObjectNotFound)
+
+	_, err = Put(ctx, store, "bogus.bin", bytes.NewReader([]byte("bogus")), "not-hex")
+	require.ErrorIs(t, err, ErrBadChecksum)
+
+	var buf bytes.Buffer
+	_, err = Get(ctx, store, "dell/bios.bin", &buf, checksum)
+	require.NoError(t, err)
+	require.Equal(t, payload, buf.Bytes())
+
+	buf.Reset()
+	_, err = Get(ctx, store, "dell/bios.bin", &buf, hex.EncodeToString(make([]byte, 32)))
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+	require.Zero(t, buf.Len())
+
+	objects, err = List(ctx, store)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	// fetch through the object URL
+	buf.Reset()
+	u, err := url.Parse(ObjectURL("firmware", "dell/bios.bin").String())
+	require.NoError(t, err)
+	_, err = GetURL(ctx, evJS, u, &buf, "")
+	require.NoError(t, err)
+	require.Equal(t, payload, buf.Bytes())
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, open := <-updates
+		return !open
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestParseObjectURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		url        string
+		wantBucket string
+		wantName   string
+		wantErr    bool
+	}{
+		{"object", "nats-obj://firmware/bios.bin", "firmware", "bios.bin", false},
+		{"nested object", "nats-obj://firmware/dell/r640/bios.bin", "firmware", "dell/r640/bios.bin", false},
+		{"wrong scheme", "https://firmware/bios.bin", "", "", true},
+		{"missing bucket", "nats-obj:///bios.bin", "", "", true},
+		{"missing name", "nats-obj://firmware/", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			require.NoError(t, err)
+
+			bucket, name, err := ParseObjectURL(u)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrBadObjectURL)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBucket, bucket)
+			require.Equal(t, tt.wantName, name)
+			require.Equal(t, tt.url, ObjectURL(bucket, name).String())
+		})
+	}
+}
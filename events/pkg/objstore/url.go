@@ -0,0 +1,72 @@
+//nolint:wsl // useless
+package objstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/metal-automata/rivets/events"
+)
+
+// Scheme is the URL scheme for objects stored in a NATS object store,
+// the URL is of the form nats-obj://<bucket>/<object name>
+const Scheme = "nats-obj"
+
+var (
+	ErrBadObjectURL = errors.New("bad object URL")
+)
+
+// ObjectURL returns the nats-obj:// URL for the object in the bucket.
+func ObjectURL(bucket, name string) *url.URL {
+	return &url.URL{
+		Scheme: Scheme,
+		Host:   bucket,
+		Path:   "/" + name,
+	}
+}
+
+// IsObjectURL returns true when the URL refers to an object in a NATS object store.
+func IsObjectURL(u *url.URL) bool {
+	return u != nil && u.Scheme == Scheme
+}
+
+// ParseObjectURL returns the bucket and object name from a nats-obj:// URL.
+func ParseObjectURL(u *url.URL) (bucket, name string, err error) {
+	if !IsObjectURL(u) {
+		return "", "", fmt.Errorf("%w: expected scheme %s://", ErrBadObjectURL, Scheme)
+	}
+
+	if u.Host == "" {
+		return "", "", fmt.Errorf("%w: missing bucket", ErrBadObjectURL)
+	}
+
+	name = strings.TrimPrefix(u.Path, "/")
+	if name == "" {
+		return "", "", fmt.Errorf("%w: missing object name", ErrBadObjectURL)
+	}
+
+	return u.Host, name, nil
+}
+
+// GetURL streams the object referred to by the nats-obj:// URL into the writer,
+// the bucket must already exist. See Get for details on the checksum verification.
+func GetURL(ctx context.Context, handle *events.NatsJetstream, u *url.URL, w io.Writer,
+	sha256Hex string) (*nats.ObjectInfo, error) {
+	bucket, name, err := ParseObjectURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := events.AsNatsJetStreamContext(handle).ObjectStore(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return Get(ctx, store, name, w, sha256Hex)
+}
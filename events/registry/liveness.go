@@ -0,0 +1,226 @@
+//nolint:wsl // useless
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+var (
+	defaultFailureThreshold = 3
+	// the check-in interval is shortened by up to this fraction of itself
+	defaultJitter = 0.1
+
+	ErrLivenessInterval = errors.New("bad liveness check-in interval")
+)
+
+// LivenessStatus is the state of a liveness check-in loop.
+type LivenessStatus struct {
+	// ConsecutiveFailures is the number of check-ins that failed in a row.
+	ConsecutiveFailures int
+	// LastError is the error from the most recent failed check-in.
+	LastError error
+	// LastCheckin is when the controller last checked in successfully.
+	LastCheckin time.Time
+}
+
+type livenessConfig struct {
+	failureThreshold int
+	jitter           float64
+	onFailure        func(id ControllerID, status LivenessStatus)
+	registerOpts     []RegisterOption
+}
+
+type LivenessOption func(c *livenessConfig)
+
+// WithFailureThreshold sets the number of consecutive failed check-ins after
+// which the controller is considered unhealthy, the default is 3.
+func WithFailureThreshold(n int) LivenessOption {
+	return func(c *livenessConfig) {
+		c.failureThreshold = n
+	}
+}
+
+// WithJitter sets the fraction of the interval, by which each check-in is randomly brought forward.
+func WithJitter(fraction float64) LivenessOption {
+	return func(c *livenessConfig) {
+		c.jitter = fraction
+	}
+}
+
+// WithFailureCallback sets a callback invoked on each failed check-in once
+// the failure threshold is reached.
+func WithFailureCallback(fn func(id ControllerID, status LivenessStatus)) LivenessOption {
+	return func(c *livenessConfig) {
+		c.onFailure = fn
+	}
+}
+
+// WithRegisterOptions sets the metadata the controller registers with.
+func WithRegisterOptions(opts ...RegisterOption) LivenessOption {
+	return func(c *livenessConfig) {
+		c.registerOpts = opts
+	}
+}
+
+// Liveness is a running check-in loop for a controller.
+type Liveness struct {
+	id        ControllerID
+	registry  *Registry
+	cfg       *livenessConfig
+	interval  time.Duration
+	mu        sync.Mutex
+	status    LivenessStatus
+	done      chan struct{}
+	deregErr  error
+	startedAt time.Time
+}
+
+// StartLiveness registers the controller and checks it in at the given interval until the
+// context is canceled, at which point the controller is deregistered.
+//
+// The controller is registered again when its entry has aged out of the registry or was
+// updated by another writer. Failed check-ins are reported through the Liveness status and
+// the failure callback.
+func (r *Registry) StartLiveness(ctx context.Context, id ControllerID, interval time.Duration,
+	opts ...LivenessOption) (*Liveness, error) {
+	if !r.initialized() {
+		return nil, ErrRegistryUninitialized
+	}
+
+	status, err := r.kv.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	if interval <= 0 || (status.TTL() > 0 && interval >= status.TTL()) {
+		return nil, fmt.Errorf("%w: interval=%s registry TTL=%s", ErrLivenessInterval, interval, status.TTL())
+	}
+
+	cfg := &livenessConfig{
+		failureThreshold: defaultFailureThreshold,
+		jitter:           defaultJitter,
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	l := &Liveness{
+		id:        id,
+		registry:  r,
+		cfg:       cfg,
+		interval:  interval,
+		done:      make(chan struct{}),
+		startedAt: time.Now(),
+	}
+
+	// retain the registration time across re-registrations
+	cfg.registerOpts = append([]RegisterOption{WithStartTime(l.startedAt)}, cfg.registerOpts...)
+
+	if err := r.Register(id, cfg.registerOpts...); err != nil {
+		return nil, err
+	}
+	l.status.LastCheckin = time.Now()
+
+	go l.run(ctx)
+
+	return l, nil
+}
+
+func StartLiveness(ctx context.Context, id ControllerID, interval time.Duration,
+	opts ...LivenessOption) (*Liveness, error) {
+	return registry.StartLiveness(ctx, id, interval, opts...)
+}
+
+// Status returns the current state of the check-in loop.
+func (l *Liveness) Status() LivenessStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.status
+}
+
+// Healthy returns false once the number of consecutive failed check-ins reaches the threshold.
+func (l *Liveness) Healthy() bool {
+	return l.Status().ConsecutiveFailures < l.cfg.failureThreshold
+}
+
+// Done returns a channel closed once the loop has stopped and the controller deregistered.
+func (l *Liveness) Done() <-chan struct{} {
+	return l.done
+}
+
+// Err returns the error from deregistering the controller, once Done is closed.
+func (l *Liveness) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.deregErr
+}
+
+func (l *Liveness) run(ctx context.Context) {
+	defer close(l.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			err := l.registry.Deregister(l.id)
+			l.mu.Lock()
+			l.deregErr = err
+			l.mu.Unlock()
+			return
+		case <-time.After(l.nextInterval()):
+			l.checkin()
+		}
+	}
+}
+
+func (l *Liveness) nextInterval() time.Duration {
+	if l.cfg.jitter <= 0 {
+		return l.interval
+	}
+	//nolint:gosec // jitter does not need a secure source
+	return l.interval - time.Duration(rand.Float64()*l.cfg.jitter*float64(l.interval))
+}
+
+func (l *Liveness) checkin() {
+	err := l.registry.Checkin(l.id)
+	if errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, nats.ErrKeyExists) || errors.Is(err, ErrBadRegistryData) {
+		// aged out, or updated by another writer
+		err = l.registry.reregister(l.id, l.cfg.registerOpts...)
+	}
+
+	l.mu.Lock()
+	if err == nil {
+		l.status = LivenessStatus{LastCheckin: time.Now()}
+		l.mu.Unlock()
+		return
+	}
+
+	l.status.ConsecutiveFailures++
+	l.status.LastError = err
+	status := l.status
+	l.mu.Unlock()
+
+	if l.cfg.onFailure != nil && status.ConsecutiveFailures >= l.cfg.failureThreshold {
+		l.cfg.onFailure(l.id, status)
+	}
+}
+
+// reregister overwrites the controller entry regardless of its revision.
+func (r *Registry) reregister(id ControllerID, opts ...RegisterOption) error {
+	active, err := json.Marshal(newActivityRecord(opts...))
+	if err != nil {
+		return err
+	}
+	rev, err := r.kv.Put(id.String(), active)
+	if err == nil {
+		id.updateVersion(rev)
+	}
+	return err
+}
@@ -8,6 +8,7 @@
 package registry
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"time"
@@ -19,7 +20,8 @@ import (
 )
 
 var (
-	registry nats.KeyValue
+	// registry is the default instance used by the package level functions.
+	registry *Registry
 
 	RegistryName  = "active-controllers"
 	registryTTL   = 3 * time.Minute
@@ -31,84 +33,215 @@ var (
 	ErrBadRegistryData               = errors.New("bad registry data")
 )
 
-func InitializeActiveControllerRegistry(njs *events.NatsJetstream) error {
-	return InitializeRegistryWithOptions(njs,
+// Registry tracks live controllers in a NATS KV bucket.
+type Registry struct {
+	kv nats.KeyValue
+}
+
+// New returns a Registry on the active controllers bucket, the bucket is
+// created with the default replication and TTL if it does not exist.
+func New(njs *events.NatsJetstream) (*Registry, error) {
+	return NewWithOptions(njs,
 		kv.WithReplicas(replicaCount),
 		kv.WithDescription(kvDescription),
 		kv.WithTTL(registryTTL),
 	)
 }
 
-// XXX: You probably don't want the un-opinionated one, but it's here.
-func InitializeRegistryWithOptions(njs *events.NatsJetstream, opts ...kv.Option) error {
-	if registry != nil {
-		return ErrRegistryPreviouslyInitialized
+// NewWithOptions returns a Registry on the active controllers bucket, the options
+// are applied when the bucket is created.
+func NewWithOptions(njs *events.NatsJetstream, opts ...kv.Option) (*Registry, error) {
+	handle, err := kv.CreateOrBindKVBucket(njs, RegistryName, opts...)
+	if err != nil {
+		return nil, err
 	}
-	var err error
-	registry, err = kv.CreateOrBindKVBucket(njs, RegistryName, opts...)
-	return err
+	return NewFromKV(handle), nil
+}
+
+// NewFromKV returns a Registry on the given KV bucket.
+func NewFromKV(handle nats.KeyValue) *Registry {
+	return &Registry{kv: handle}
 }
 
-func proofOfLife() ([]byte, error) {
-	active := &activityRecord{
-		LastActive: time.Now(),
+// KeyValue exposes the underlying nats.KeyValue
+func (r *Registry) KeyValue() nats.KeyValue {
+	if r == nil {
+		return nil
 	}
-	return json.Marshal(active)
+	return r.kv
+}
+
+func (r *Registry) initialized() bool {
+	return r != nil && r.kv != nil
 }
 
-func RegisterController(id ControllerID) error {
-	if registry == nil {
+// Register adds the controller to the registry, the options set metadata
+// on the controller ActivityRecord.
+func (r *Registry) Register(id ControllerID, opts ...RegisterOption) error {
+	if !r.initialized() {
 		return ErrRegistryUninitialized
 	}
-	active, err := proofOfLife()
+	active, err := json.Marshal(newActivityRecord(opts...))
 	if err != nil {
 		return err
 	}
-	rev, err := registry.Create(id.String(), active)
+	rev, err := r.kv.Create(id.String(), active)
 	if err == nil {
 		id.updateVersion(rev)
 	}
 	return err
 }
 
-func ControllerCheckin(id ControllerID) error {
-	if registry == nil {
+// Checkin refreshes the controller entry in the registry before its TTL expires.
+func (r *Registry) Checkin(id ControllerID) error {
+	if !r.initialized() {
 		return ErrRegistryUninitialized
 	}
-	active, err := proofOfLife()
+	// carry over the metadata from registration
+	entry, err := r.kv.Get(id.String())
+	if err != nil {
+		return err
+	}
+	var ar ActivityRecord
+	if err = json.Unmarshal(entry.Value(), &ar); err != nil {
+		return ErrBadRegistryData
+	}
+	ar.LastActive = time.Now()
+	active, err := json.Marshal(&ar)
 	if err != nil {
 		return err
 	}
-	rev, err := registry.Update(id.String(), active, id.version())
+	rev, err := r.kv.Update(id.String(), active, id.version())
 	if err == nil {
 		id.updateVersion(rev)
 	}
 	return err
 }
 
-func DeregisterController(id ControllerID) error {
-	if registry == nil {
+// Deregister removes the controller from the registry.
+func (r *Registry) Deregister(id ControllerID) error {
+	if !r.initialized() {
 		return ErrRegistryUninitialized
 	}
-	return registry.Delete(id.String())
+	return r.kv.Delete(id.String())
 }
 
-func LastContact(id ControllerID) (time.Time, error) {
+// LastContact returns the time the controller last checked in.
+func (r *Registry) LastContact(id ControllerID) (time.Time, error) {
 	var zt time.Time
-	if registry == nil {
-		return zt, ErrRegistryUninitialized
+	ar, err := r.Activity(id)
+	if err != nil {
+		return zt, err
 	}
-	entry, err := registry.Get(id.String())
+	return ar.LastActive, nil
+}
+
+// Activity returns the ActivityRecord of the controller.
+func (r *Registry) Activity(id ControllerID) (*ActivityRecord, error) {
+	if !r.initialized() {
+		return nil, ErrRegistryUninitialized
+	}
+	entry, err := r.kv.Get(id.String())
 	if err != nil {
-		return zt, err // this can either be a communication error or nats.ErrKeyNotFound
+		return nil, err // this can either be a communication error or nats.ErrKeyNotFound
 	}
 	// if we have an entry the controller was alive in the last TTL period
-	var ar activityRecord
+	var ar ActivityRecord
 	err = json.Unmarshal(entry.Value(), &ar)
 	if err != nil {
-		return zt, ErrBadRegistryData // consumers should *probably* treat this as a success?
+		return nil, ErrBadRegistryData // consumers should *probably* treat this as a success?
 	}
-	return ar.LastActive, nil
+	return &ar, nil
+}
+
+// ListControllers returns the live controllers matching the filter, a nil filter returns all controllers.
+//
+// Entries that cannot be parsed are skipped.
+func (r *Registry) ListControllers(ctx context.Context, filter *ControllerFilter) ([]*Controller, error) {
+	if !r.initialized() {
+		return nil, ErrRegistryUninitialized
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, err := kv.NewTyped[ActivityRecord](r.kv).Watch(ctx, nil, kv.WatchIgnoreDeletes())
+	if err != nil {
+		return nil, err
+	}
+
+	controllers := []*Controller{}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case ev, ok := <-updates:
+			if !ok {
+				return nil, ctx.Err()
+			}
+			if ev.Op == kv.OpSynced {
+				return controllers, nil
+			}
+			if ev.Err != nil {
+				continue
+			}
+			id, err := ControllerIDFromString(ev.Key)
+			if err != nil {
+				continue
+			}
+			if !filter.match(id, &ev.Value) {
+				continue
+			}
+			controllers = append(controllers, &Controller{ID: id, Activity: ev.Value})
+		}
+	}
+}
+
+// Default returns the registry used by the package level functions, nil is
+// returned when it has not been initialized.
+func Default() *Registry {
+	return registry
+}
+
+func InitializeActiveControllerRegistry(njs *events.NatsJetstream) error {
+	return InitializeRegistryWithOptions(njs,
+		kv.WithReplicas(replicaCount),
+		kv.WithDescription(kvDescription),
+		kv.WithTTL(registryTTL),
+	)
+}
+
+// XXX: You probably don't want the un-opinionated one, but it's here.
+func InitializeRegistryWithOptions(njs *events.NatsJetstream, opts ...kv.Option) error {
+	if registry != nil {
+		return ErrRegistryPreviouslyInitialized
+	}
+	r, err := NewWithOptions(njs, opts...)
+	if err != nil {
+		return err
+	}
+	registry = r
+	return nil
+}
+
+func RegisterController(id ControllerID, opts ...RegisterOption) error {
+	return registry.Register(id, opts...)
+}
+
+func ControllerCheckin(id ControllerID) error {
+	return registry.Checkin(id)
+}
+
+func DeregisterController(id ControllerID) error {
+	return registry.Deregister(id)
+}
+
+func LastContact(id ControllerID) (time.Time, error) {
+	return registry.LastContact(id)
+}
+
+func ListControllers(ctx context.Context, filter *ControllerFilter) ([]*Controller, error) {
+	return registry.ListControllers(ctx, filter)
 }
 
 // SetHandle is a helper method which assigns the nats.KeyValue to the pkg global registry
@@ -117,7 +250,7 @@ func SetHandle(handle nats.KeyValue) error {
 		return ErrRegistryPreviouslyInitialized
 	}
 
-	registry = handle
+	registry = NewFromKV(handle)
 
 	return nil
 }
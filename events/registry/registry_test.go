@@ -2,13 +2,16 @@
 package registry
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/require"
 
 	"github.com/metal-automata/rivets/events"
 	kvTest "github.com/metal-automata/rivets/events/internal/test"
+	"github.com/metal-automata/rivets/events/pkg/kv"
 )
 
 func TestAppLifecycle(t *testing.T) {
@@ -55,3 +58,330 @@ func TestAppLifecycle(t *testing.T) {
 	require.Error(t, err)
 	require.ErrorIs(t, err, nats.ErrKeyNotFound)
 }
+
+func TestRegistryInstances(t *testing.T) {
+	t.Parallel()
+	var uninitialized *Registry
+	id := GetID("testApp")
+	require.Equal(t, ErrRegistryUninitialized, uninitialized.Register(id))
+
+	// registries on separate servers are independent of each other
+	srv1 := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv1)
+	nc1, _ := kvTest.JetStreamContext(t, srv1)
+	evJS1 := events.NewJetstreamFromConn(nc1)
+	defer evJS1.Close()
+
+	srv2 := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv2)
+	nc2, _ := kvTest.JetStreamContext(t, srv2)
+	evJS2 := events.NewJetstreamFromConn(nc2)
+	defer evJS2.Close()
+
+	r1, err := NewWithOptions(evJS1)
+	require.NoError(t, err)
+	r2, err := NewWithOptions(evJS2)
+	require.NoError(t, err)
+
+	// binding to an existing bucket is not an error
+	_, err = NewWithOptions(evJS1)
+	require.NoError(t, err)
+
+	require.NoError(t, r1.Register(id))
+	require.NoError(t, r1.Checkin(id))
+	_, err = r1.LastContact(id)
+	require.NoError(t, err)
+
+	_, err = r2.LastContact(id)
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+
+	require.NoError(t, r1.Deregister(id))
+	_, err = r1.LastContact(id)
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+}
+
+func TestListControllers(t *testing.T) {
+	t.Parallel()
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	r, err := NewWithOptions(evJS)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	controllers, err := r.ListControllers(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, controllers)
+
+	started := time.Now().Add(-time.Hour).Truncate(time.Second)
+	flasher1 := GetID("flasher")
+	flasher2 := GetID("flasher")
+	bioscfg := GetID("bioscfg")
+
+	require.NoError(t, r.Register(flasher1,
+		WithFacility("fc13"),
+		WithConditionKinds("firmwareInstall"),
+		WithStartTime(started),
+		WithHostname("worker-1"),
+		WithVersion("v1.2.3"),
+	))
+	require.NoError(t, r.Register(flasher2, WithFacility("fc42")))
+	require.NoError(t, r.Register(bioscfg, WithFacility("fc13")))
+
+	// metadata is retained on checkin
+	require.NoError(t, r.Checkin(flasher1))
+	ar, err := r.Activity(flasher1)
+	require.NoError(t, err)
+	require.Equal(t, "fc13", ar.Facility)
+	require.Equal(t, []string{"firmwareInstall"}, ar.ConditionKinds)
+	require.Equal(t, "worker-1", ar.Hostname)
+	require.Equal(t, "v1.2.3", ar.Version)
+	require.True(t, started.Equal(ar.StartedAt))
+	require.True(t, ar.LastActive.After(started))
+
+	// defaults are populated
+	ar, err = r.Activity(flasher2)
+	require.NoError(t, err)
+	require.NotEmpty(t, ar.Hostname)
+	require.Equal(t, "dev", ar.Version)
+	require.False(t, ar.StartedAt.IsZero())
+
+	ids := func(cs []*Controller) []string {
+		var s []string
+		for _, c := range cs {
+			s = append(s, c.ID.String())
+		}
+		return s
+	}
+
+	controllers, err = r.ListControllers(ctx, nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{flasher1.String(), flasher2.String(), bioscfg.String()}, ids(controllers))
+
+	controllers, err = r.ListControllers(ctx, &ControllerFilter{AppName: "flasher"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{flasher1.String(), flasher2.String()}, ids(controllers))
+
+	controllers, err = r.ListControllers(ctx, &ControllerFilter{Facility: "fc13"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{flasher1.String(), bioscfg.String()}, ids(controllers))
+
+	controllers, err = r.ListControllers(ctx, &ControllerFilter{AppName: "flasher", Facility: "fc13"})
+	require.NoError(t, err)
+	require.Len(t, controllers, 1)
+	require.Equal(t, "worker-1", controllers[0].Activity.Hostname)
+
+	require.NoError(t, r.Deregister(flasher1))
+	controllers, err = r.ListControllers(ctx, &ControllerFilter{AppName: "flasher"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{flasher2.String()}, ids(controllers))
+}
+
+func TestRegistryWatch(t *testing.T) {
+	t.Parallel()
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	r, err := NewWithOptions(evJS, kv.WithTTL(time.Second))
+	require.NoError(t, err)
+
+	existing := GetID("existing")
+	require.NoError(t, r.Register(existing))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := r.Watch(ctx)
+	require.NoError(t, err)
+
+	next := func() Event {
+		t.Helper()
+		select {
+		case ev := <-ch:
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for registry event")
+		}
+		return Event{}
+	}
+
+	ev := next()
+	require.Equal(t, ControllerJoined, ev.Kind)
+	require.Equal(t, existing.String(), ev.ID.String())
+
+	worker := GetID("worker")
+	require.NoError(t, r.Register(worker, WithFacility("fc13")))
+	ev = next()
+	require.Equal(t, ControllerJoined, ev.Kind)
+	require.Equal(t, worker.String(), ev.ID.String())
+	require.Equal(t, "fc13", ev.Activity.Facility)
+
+	require.NoError(t, r.Checkin(worker))
+	ev = next()
+	require.Equal(t, ControllerCheckedIn, ev.Kind)
+	require.Equal(t, worker.String(), ev.ID.String())
+
+	require.NoError(t, r.Deregister(worker))
+	ev = next()
+	require.Equal(t, ControllerLeft, ev.Kind)
+	require.Equal(t, worker.String(), ev.ID.String())
+	require.Equal(t, "fc13", ev.Activity.Facility)
+
+	// the existing controller never checks in and ages out
+	ev = next()
+	require.Equal(t, ControllerExpired, ev.Kind)
+	require.Equal(t, existing.String(), ev.ID.String())
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, open := <-ch
+		return !open
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestLiveness(t *testing.T) {
+	t.Parallel()
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	r, err := NewWithOptions(evJS, kv.WithTTL(time.Second))
+	require.NoError(t, err)
+
+	id := GetID("worker")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err = r.StartLiveness(ctx, id, time.Second)
+	require.ErrorIs(t, err, ErrLivenessInterval)
+
+	l, err := r.StartLiveness(ctx, id, 200*time.Millisecond, WithRegisterOptions(WithFacility("fc13")))
+	require.NoError(t, err)
+
+	// outlives the registry TTL
+	time.Sleep(1500 * time.Millisecond)
+	_, err = r.LastContact(id)
+	require.NoError(t, err)
+	require.True(t, l.Healthy())
+
+	// entry overwritten by another writer
+	_, err = r.KeyValue().Put(id.String(), []byte(`{}`))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		ar, err := r.Activity(id)
+		return err == nil && ar.Facility == "fc13"
+	}, 2*time.Second, 50*time.Millisecond)
+
+	// entry removed
+	require.NoError(t, r.Deregister(id))
+	require.Eventually(t, func() bool {
+		_, err := r.LastContact(id)
+		return err == nil
+	}, 2*time.Second, 50*time.Millisecond)
+	require.True(t, l.Healthy())
+
+	cancel()
+	select {
+	case <-l.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("liveness loop did not stop")
+	}
+	require.NoError(t, l.Err())
+	_, err = r.LastContact(id)
+	require.ErrorIs(t, err, nats.ErrKeyNotFound)
+}
+
+func TestLivenessFailures(t *testing.T) {
+	t.Parallel()
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+
+	r, err := NewWithOptions(evJS)
+	require.NoError(t, err)
+
+	failures := make(chan LivenessStatus, 10)
+	id := GetID("worker")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l, err := r.StartLiveness(ctx, id, 50*time.Millisecond,
+		WithFailureThreshold(2),
+		WithJitter(0),
+		WithFailureCallback(func(_ ControllerID, status LivenessStatus) {
+			select {
+			case failures <- status:
+			default:
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	// lose the connection to the registry
+	evJS.Close()
+
+	select {
+	case status := <-failures:
+		require.GreaterOrEqual(t, status.ConsecutiveFailures, 2)
+		require.Error(t, status.LastError)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected failure callback")
+	}
+	require.False(t, l.Healthy())
+
+	cancel()
+	<-l.Done()
+	require.Error(t, l.Err())
+}
+
+func TestCapableControllers(t *testing.T) {
+	t.Parallel()
+	srv := kvTest.StartJetStreamServer(t)
+	defer kvTest.ShutdownJetStream(t, srv)
+	nc, _ := kvTest.JetStreamContext(t, srv)
+	evJS := events.NewJetstreamFromConn(nc)
+	defer evJS.Close()
+
+	r, err := NewWithOptions(evJS)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = r.CapableControllers(ctx, "firmwareInstall", "fc13")
+	var errNone *ErrNoCapableController
+	require.ErrorAs(t, err, &errNone)
+	require.Equal(t, "firmwareInstall", errNone.ConditionKind)
+	require.Equal(t, "fc13", errNone.Facility)
+
+	flasher := GetID("flasher")
+	require.NoError(t, r.Register(flasher,
+		WithFacility("fc13"),
+		WithFacilities("fc42"),
+		WithConditionKinds("firmwareInstall", "inventory"),
+	))
+
+	// controllers that don't advertise capabilities are excluded
+	require.NoError(t, r.Register(GetID("legacy"), WithFacility("fc13")))
+
+	for _, facility := range []string{"fc13", "fc42"} {
+		controllers, err := r.CapableControllers(ctx, "firmwareInstall", facility)
+		require.NoError(t, err)
+		require.Len(t, controllers, 1)
+		require.Equal(t, flasher.String(), controllers[0].ID.String())
+	}
+
+	_, err = r.CapableControllers(ctx, "firmwareInstall", "fc99")
+	require.ErrorAs(t, err, &errNone)
+
+	_, err = r.CapableControllers(ctx, "serverControl", "fc13")
+	require.ErrorAs(t, err, &errNone)
+}
@@ -0,0 +1,43 @@
+//nolint:wsl // useless
+package registry
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrNoCapableController is returned when no live controller advertises it can
+// handle a Condition kind in a facility.
+type ErrNoCapableController struct {
+	ConditionKind string
+	Facility      string
+}
+
+func (e *ErrNoCapableController) Error() string {
+	return fmt.Sprintf("no live controller handles condition kind=%s in facility=%s", e.ConditionKind, e.Facility)
+}
+
+// CapableControllers returns the live controllers that advertised they handle the Condition kind
+// in the facility, see WithConditionKinds and WithFacilities.
+//
+// Controllers that did not advertise their capabilities are not included, when there are no
+// capable controllers an *ErrNoCapableController is returned.
+func (r *Registry) CapableControllers(ctx context.Context, conditionKind, facility string) ([]*Controller, error) {
+	controllers, err := r.ListControllers(ctx, &ControllerFilter{
+		ConditionKind: conditionKind,
+		Facility:      facility,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(controllers) == 0 {
+		return nil, &ErrNoCapableController{ConditionKind: conditionKind, Facility: facility}
+	}
+
+	return controllers, nil
+}
+
+func CapableControllers(ctx context.Context, conditionKind, facility string) ([]*Controller, error) {
+	return registry.CapableControllers(ctx, conditionKind, facility)
+}
@@ -4,10 +4,14 @@ package registry
 import (
 	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/metal-automata/rivets/version"
 )
 
 var (
@@ -67,6 +71,133 @@ func GetIDWithUUID(app string, id uuid.UUID) ControllerID {
 	}
 }
 
-type activityRecord struct {
+// ActivityRecord is the registry entry for a live controller.
+//
+// Apart from LastActive the fields are optional metadata set when the controller registers.
+//
+// nolint:govet // fieldalignment struct is easier to read in the current format
+type ActivityRecord struct {
+	// LastActive is when the controller last checked in.
 	LastActive time.Time `json:"last_active"`
+
+	// StartedAt is when the controller registered.
+	StartedAt time.Time `json:"started_at,omitempty"`
+
+	// Hostname of the host the controller runs on.
+	Hostname string `json:"hostname,omitempty"`
+
+	// Version is the controller release version.
+	Version string `json:"version,omitempty"`
+
+	// Facility is the facility code the controller runs in.
+	Facility string `json:"facility,omitempty"`
+
+	// Facilities are additional facility codes the controller serves.
+	Facilities []string `json:"facilities,omitempty"`
+
+	// ConditionKinds are the kinds of Conditions the controller handles.
+	ConditionKinds []string `json:"condition_kinds,omitempty"`
+}
+
+// ServesFacility returns true when the controller advertised it serves the facility.
+func (ar *ActivityRecord) ServesFacility(facility string) bool {
+	return ar.Facility == facility || slices.Contains(ar.Facilities, facility)
+}
+
+// HandlesConditionKind returns true when the controller advertised it handles the Condition kind.
+func (ar *ActivityRecord) HandlesConditionKind(kind string) bool {
+	return slices.Contains(ar.ConditionKinds, kind)
+}
+
+// RegisterOption sets metadata on the ActivityRecord of a registering controller.
+type RegisterOption func(ar *ActivityRecord)
+
+func WithHostname(hostname string) RegisterOption {
+	return func(ar *ActivityRecord) {
+		ar.Hostname = hostname
+	}
+}
+
+func WithVersion(v string) RegisterOption {
+	return func(ar *ActivityRecord) {
+		ar.Version = v
+	}
+}
+
+func WithFacility(facility string) RegisterOption {
+	return func(ar *ActivityRecord) {
+		ar.Facility = facility
+	}
+}
+
+func WithFacilities(facilities ...string) RegisterOption {
+	return func(ar *ActivityRecord) {
+		ar.Facilities = facilities
+	}
+}
+
+func WithConditionKinds(kinds ...string) RegisterOption {
+	return func(ar *ActivityRecord) {
+		ar.ConditionKinds = kinds
+	}
+}
+
+func WithStartTime(t time.Time) RegisterOption {
+	return func(ar *ActivityRecord) {
+		ar.StartedAt = t
+	}
+}
+
+// newActivityRecord returns a record populated with the host name, the release version
+// from the version package and the current time as the start time, the options
+// override these.
+func newActivityRecord(opts ...RegisterOption) *ActivityRecord {
+	now := time.Now()
+	//nolint:errcheck // the hostname is informational
+	hostname, _ := os.Hostname()
+
+	ar := &ActivityRecord{
+		LastActive: now,
+		StartedAt:  now,
+		Hostname:   hostname,
+		Version:    version.Version(),
+	}
+	for _, o := range opts {
+		o(ar)
+	}
+	return ar
+}
+
+// ControllerFilter selects controllers in ListControllers, empty fields match all controllers.
+type ControllerFilter struct {
+	AppName       string
+	Facility      string
+	ConditionKind string
+}
+
+func (f *ControllerFilter) match(id ControllerID, ar *ActivityRecord) bool {
+	if f == nil {
+		return true
+	}
+	if f.AppName != "" && appName(id) != f.AppName {
+		return false
+	}
+	if f.Facility != "" && !ar.ServesFacility(f.Facility) {
+		return false
+	}
+	if f.ConditionKind != "" && !ar.HandlesConditionKind(f.ConditionKind) {
+		return false
+	}
+	return true
+}
+
+func appName(id ControllerID) string {
+	name, _, _ := strings.Cut(id.String(), "/")
+	return name
+}
+
+// Controller is a live controller in the registry.
+type Controller struct {
+	ID       ControllerID
+	Activity ActivityRecord
 }
@@ -0,0 +1,159 @@
+//nolint:wsl // useless
+package registry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/metal-automata/rivets/events/pkg/kv"
+)
+
+// EventKind identifies a change in the set of live controllers.
+type EventKind string
+
+const (
+	// ControllerJoined is sent when a controller registers, and for the
+	// controllers already registered when the watch starts.
+	ControllerJoined EventKind = "joined"
+
+	// ControllerCheckedIn is sent when a controller checks in.
+	ControllerCheckedIn EventKind = "checkedIn"
+
+	// ControllerLeft is sent when a controller deregisters.
+	ControllerLeft EventKind = "left"
+
+	// ControllerExpired is sent when a controller entry aged out of the
+	// registry after the controller failed to check in.
+	ControllerExpired EventKind = "expired"
+)
+
+// Event is a change in the set of live controllers.
+//
+// For Left and Expired events Activity holds the last known record for the controller.
+type Event struct {
+	Kind     EventKind
+	ID       ControllerID
+	Activity ActivityRecord
+	Time     time.Time
+}
+
+type watched struct {
+	id       ControllerID
+	activity ActivityRecord
+	updated  time.Time
+}
+
+// Watch sends changes in the set of live controllers on the returned channel,
+// until the context is canceled.
+//
+// NATS does not signal when a key ages out of a bucket, expired controllers are
+// detected by checking for entries not updated within the bucket TTL. Expired
+// events are sent only when the bucket has a TTL.
+func (r *Registry) Watch(ctx context.Context) (<-chan Event, error) {
+	if !r.initialized() {
+		return nil, ErrRegistryUninitialized
+	}
+
+	status, err := r.kv.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	updates, err := kv.NewTyped[ActivityRecord](r.kv).Watch(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Event)
+	go r.watch(ctx, updates, status.TTL(), ch)
+
+	return ch, nil
+}
+
+//nolint:gocyclo // event handling is cyclomatic
+func (r *Registry) watch(ctx context.Context, updates <-chan kv.WatchEvent[ActivityRecord], ttl time.Duration, ch chan<- Event) {
+	defer close(ch)
+
+	known := map[string]*watched{}
+
+	var expiryCheck <-chan time.Time
+	if ttl > 0 {
+		ticker := time.NewTicker(ttl / 10)
+		defer ticker.Stop()
+		expiryCheck = ticker.C
+	}
+
+	send := func(ev Event) bool {
+		select {
+		case ch <- ev:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-expiryCheck:
+			for key, w := range known {
+				if time.Since(w.updated) < ttl {
+					continue
+				}
+				// confirm the entry is gone
+				if _, err := r.kv.Get(key); !errors.Is(err, nats.ErrKeyNotFound) {
+					continue
+				}
+				delete(known, key)
+				if !send(Event{Kind: ControllerExpired, ID: w.id, Activity: w.activity, Time: time.Now()}) {
+					return
+				}
+			}
+
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			if update.Op == kv.OpSynced || update.Err != nil {
+				continue
+			}
+			id, err := ControllerIDFromString(update.Key)
+			if err != nil {
+				continue
+			}
+
+			w, exists := known[update.Key]
+			ev := Event{ID: id, Time: update.Created}
+
+			switch update.Op {
+			case kv.OpDelete, kv.OpPurge:
+				if !exists {
+					continue
+				}
+				delete(known, update.Key)
+				ev.Kind = ControllerLeft
+				ev.Activity = w.activity
+			default:
+				ev.Kind = ControllerCheckedIn
+				if !exists {
+					ev.Kind = ControllerJoined
+				}
+				ev.Activity = update.Value
+				known[update.Key] = &watched{id: id, activity: update.Value, updated: update.Created}
+			}
+
+			if !send(ev) {
+				return
+			}
+		}
+	}
+}
+
+func Watch(ctx context.Context) (<-chan Event, error) {
+	return registry.Watch(ctx)
+}